@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,6 +30,325 @@ type OperatorConfigSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Paused stops the operator from reconciling the managed resources. They
+	// are still cleaned up when the OperatorConfig is deleted.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Drift configures how changes made to the managed resources outside of
+	// the operator are handled
+	// +optional
+	Drift *DriftPolicy `json:"drift,omitempty"`
+
+	// Overrides are patches applied on top of the rendered manifests before
+	// they are applied to the cluster. Overrides setting the image of a
+	// container or step are rejected, images are selected by image key.
+	// +optional
+	Overrides []Override `json:"overrides,omitempty"`
+
+	// ImageMirrors rewrite the images of the operands, including the ones
+	// referenced in ClusterTask scripts, to pull them from a mirror registry.
+	// The first matching mirror is used.
+	// +optional
+	ImageMirrors []ImageMirror `json:"imageMirrors,omitempty"`
+
+	// RequireImageDigests rejects operand images not pinned by a sha256
+	// digest. While any image is unpinned nothing is applied and the
+	// Degraded condition lists the offending images.
+	// +optional
+	RequireImageDigests bool `json:"requireImageDigests,omitempty"`
+
+	// ImagePullSecrets are added to the pods of the operand Deployments to
+	// pull the operand images, e.g. from a mirror registry
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ImagePullPolicy replaces the pull policy of the operand containers and
+	// ClusterTask steps. The manifests default to Always.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Proxy replaces the settings of the cluster wide config.openshift.io
+	// Proxy passed to the operands. An empty proxy disables the proxy.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// TrustedCA configures the CA bundle the operands trust when connecting
+	// to remote clusters
+	// +optional
+	TrustedCA *TrustedCAConfig `json:"trustedCA,omitempty"`
+
+	// TLSSecurityProfile configures the TLS versions and ciphers of the
+	// operand servers. The profile of the cluster wide APIServer is used
+	// when unset.
+	// +optional
+	TLSSecurityProfile *configv1.TLSSecurityProfile `json:"tlsSecurityProfile,omitempty"`
+
+	// Platform the operands are installed on. Auto detects OpenShift by the
+	// config.openshift.io APIs, console resources and service-ca annotations
+	// are only used on OpenShift.
+	// +kubebuilder:default=Auto
+	// +optional
+	Platform Platform `json:"platform,omitempty"`
+
+	// Certificates configures how the serving certificates of the reverse
+	// proxy and secret service are provided
+	// +optional
+	Certificates *CertificatesConfig `json:"certificates,omitempty"`
+
+	// Expose makes operands reachable from outside of the cluster, through a
+	// reencrypt Route on OpenShift or an Ingress on Kubernetes
+	// +optional
+	Expose []ExposeConfig `json:"expose,omitempty"`
+
+	// NetworkPolicy configures the NetworkPolicies restricting the traffic
+	// of the reverse proxy and secret service
+	// +optional
+	NetworkPolicy *NetworkPolicyConfig `json:"networkPolicy,omitempty"`
+
+	// EnableConsolePlugin adds the crane-ui-plugin to the plugins of the
+	// console.operator.openshift.io Console named cluster when true, and
+	// removes it when false. The Console is left as it is when unset, e.g.
+	// as configured by the OLM install.
+	// +optional
+	EnableConsolePlugin *bool `json:"enableConsolePlugin,omitempty"`
+
+	// Tasks configures how the crane-runner Tekton tasks are installed,
+	// ClusterTasks are deprecated by Tekton in favor of namespaced Tasks
+	// +optional
+	Tasks *TasksConfig `json:"tasks,omitempty"`
+}
+
+// TaskKind is the kind of Tekton task the crane-runner tasks are installed as
+// +kubebuilder:validation:Enum=ClusterTask;Task;Both
+type TaskKind string
+
+const (
+	TaskKindClusterTask TaskKind = "ClusterTask"
+	TaskKindTask        TaskKind = "Task"
+	TaskKindBoth        TaskKind = "Both"
+)
+
+// TasksConfig configures the crane-runner Tekton tasks
+type TasksConfig struct {
+	// Kind of the tasks, ClusterTask, Task or Both
+	// +kubebuilder:default=ClusterTask
+	// +optional
+	Kind TaskKind `json:"kind,omitempty"`
+
+	// Namespaces the Tasks are installed in. They default to the install
+	// namespace, which pipelines can use as a catalog through the Tekton
+	// cluster resolver.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// NetworkPolicyConfig configures the NetworkPolicies of the operands. Ingress
+// is allowed from the console and from Tekton pipeline pods.
+type NetworkPolicyConfig struct {
+	// Disabled stops the operator from creating NetworkPolicies, the ones it
+	// created are deleted
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// IngressNamespaces are further namespaces allowed to connect to the
+	// operands, e.g. the namespace of the ingress controller on Kubernetes
+	// +optional
+	IngressNamespaces []string `json:"ingressNamespaces,omitempty"`
+
+	// EgressCIDRs restrict the connections of the operands to these CIDRs,
+	// they have to include the API servers of the local and remote clusters.
+	// DNS is always allowed. Egress is not restricted when empty.
+	// +optional
+	EgressCIDRs []string `json:"egressCIDRs,omitempty"`
+}
+
+// ExposeConfig configures the Route or Ingress of an operand
+type ExposeConfig struct {
+	// Component to expose
+	// +kubebuilder:validation:Enum=proxy;secret-service
+	Component string `json:"component"`
+
+	// Host name of the Route or Ingress. Routes without a host get one
+	// generated by the router.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// TLSSecretName is a kubernetes.io/tls Secret in the install namespace
+	// with the certificate served for the host. The default certificate of
+	// the router or ingress controller is used when unset.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// IngressClassName of the Ingress, ignored on OpenShift
+	// +optional
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// Annotations added to the Route or Ingress, e.g. to configure the
+	// ingress controller
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CertificateProvider provides the serving certificates of the operands
+// +kubebuilder:validation:Enum=Auto;ServiceCA;SelfSigned;CertManager
+type CertificateProvider string
+
+const (
+	// CertificateProviderAuto uses the service CA on OpenShift and self-signed
+	// certificates everywhere else
+	CertificateProviderAuto CertificateProvider = "Auto"
+	// CertificateProviderServiceCA uses the OpenShift service CA operator
+	CertificateProviderServiceCA CertificateProvider = "ServiceCA"
+	// CertificateProviderSelfSigned uses certificates generated by the
+	// operator
+	CertificateProviderSelfSigned CertificateProvider = "SelfSigned"
+	// CertificateProviderCertManager uses certificates issued by cert-manager
+	CertificateProviderCertManager CertificateProvider = "CertManager"
+)
+
+// CertificatesConfig configures the serving certificates of the operands
+type CertificatesConfig struct {
+	// Provider of the serving certificates
+	// +kubebuilder:default=Auto
+	// +optional
+	Provider CertificateProvider `json:"provider,omitempty"`
+
+	// IssuerRef is the cert-manager Issuer or ClusterIssuer issuing the
+	// certificates, required by the CertManager provider
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+}
+
+// IssuerReference references a cert-manager issuer
+type IssuerReference struct {
+	// Name of the issuer
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the issuer, Issuer or ClusterIssuer. Issuers have to be in the
+	// install namespace.
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer, cert-manager.io unless an external issuer is used
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// Platform is the kind of cluster the operands are installed on
+// +kubebuilder:validation:Enum=Auto;OpenShift;Kubernetes
+type Platform string
+
+const (
+	PlatformAuto       Platform = "Auto"
+	PlatformOpenShift  Platform = "OpenShift"
+	PlatformKubernetes Platform = "Kubernetes"
+)
+
+// TrustedCAConfig configures the CA bundle mounted into the operands
+type TrustedCAConfig struct {
+	// CABundle is a PEM encoded CA bundle replacing the trusted CA bundle of
+	// the cluster, which is injected by default. It has to include the
+	// public CAs the operands should trust too.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// VerifyRemoteTLS drops the --insecure-skip-tls-verify flags from the
+	// ClusterTask scripts, so the certificates of remote clusters are
+	// verified against the trusted CA bundle
+	// +optional
+	VerifyRemoteTLS bool `json:"verifyRemoteTLS,omitempty"`
+}
+
+// ProxyConfig are the HTTP proxy settings passed to the operand containers
+// and ClusterTask steps as the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env vars
+type ProxyConfig struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames and CIDRs the proxy is
+	// not used for
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ImageMirror replaces the Source prefix of image references with Mirror
+type ImageMirror struct {
+	// Source is the repository prefix to replace, e.g. quay.io/konveyor
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// Mirror is the repository prefix to pull from instead, e.g.
+	// registry.example.com/konveyor
+	// +kubebuilder:validation:MinLength=1
+	Mirror string `json:"mirror"`
+}
+
+// CertificateStatus reports a certificate generated by the operator
+type CertificateStatus struct {
+	// SecretName is the name of the Secret holding the certificate
+	SecretName string `json:"secretName"`
+
+	// NotAfter is when the certificate expires, it is renewed once two thirds
+	// of its lifetime passed
+	NotAfter metav1.Time `json:"notAfter"`
+}
+
+// ImageRewrite reports an image reference rewritten by an image mirror
+type ImageRewrite struct {
+	Source string `json:"source"`
+	Mirror string `json:"mirror"`
+}
+
+// DriftMode defines what the operator does when a managed resource no longer
+// matches its rendered manifest
+// +kubebuilder:validation:Enum=Correct;Report
+type DriftMode string
+
+const (
+	// DriftModeCorrect overwrites drifted fields with the rendered manifest
+	DriftModeCorrect DriftMode = "Correct"
+	// DriftModeReport leaves drifted resources untouched and reports them in
+	// the status and as events
+	DriftModeReport DriftMode = "Report"
+)
+
+// DriftPolicy defines how drift between the rendered manifests and the live
+// resources is handled
+type DriftPolicy struct {
+	// Mode is either Correct (default) or Report
+	// +optional
+	Mode DriftMode `json:"mode,omitempty"`
+
+	// Ignore lists fields the operator must never overwrite, regardless of the
+	// mode
+	// +optional
+	Ignore []DriftIgnore `json:"ignore,omitempty"`
+}
+
+// DriftIgnore selects fields of managed resources left as they are found in
+// the cluster
+type DriftIgnore struct {
+	// Kind of the managed resource, e.g. Deployment
+	Kind string `json:"kind"`
+
+	// Name of the managed resource, every resource of the kind when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Paths are dot separated paths of the ignored fields, e.g. spec.replicas.
+	// A list element is selected by its index or by the value of one of its
+	// fields, e.g. spec.template.spec.containers[0].resources or
+	// spec.template.spec.containers[name=proxy].env
+	Paths []string `json:"paths"`
 }
 
 // OperatorConfigStatus defines the observed state of OperatorConfig
@@ -37,6 +358,118 @@ type OperatorConfigStatus struct {
 
 	// Conditions for operator config status
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// Drift lists the managed resources that differ from their rendered
+	// manifests and were not corrected
+	// +optional
+	Drift []ResourceDrift `json:"drift,omitempty"`
+
+	// Unmanaged lists the resources skipped because they carry the
+	// crane.konveyor.io/unmanaged annotation
+	// +optional
+	Unmanaged []ResourceReference `json:"unmanaged,omitempty"`
+
+	// InvalidOverrides lists the overrides that could not be applied
+	// +optional
+	InvalidOverrides []OverrideError `json:"invalidOverrides,omitempty"`
+
+	// InvalidDriftIgnores lists the drift ignore paths that could not be
+	// applied
+	// +optional
+	InvalidDriftIgnores []DriftIgnoreError `json:"invalidDriftIgnores,omitempty"`
+
+	// RewrittenImages lists the image references rewritten by the image
+	// mirrors
+	// +optional
+	RewrittenImages []ImageRewrite `json:"rewrittenImages,omitempty"`
+
+	// Platform is the platform the operands were last installed for
+	// +optional
+	Platform Platform `json:"platform,omitempty"`
+
+	// Certificates lists the certificates generated by the operator when the
+	// OpenShift service CA is not available
+	// +optional
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+
+	// ConsolePluginEnabled reports whether the console loads the
+	// crane-ui-plugin
+	// +optional
+	ConsolePluginEnabled bool `json:"consolePluginEnabled,omitempty"`
+
+	// MissingAPIs lists the APIs not served by the cluster, e.g. the Tekton
+	// ClusterTask API before the pipelines operator is installed. The
+	// operands using them are installed once they are served.
+	// +optional
+	MissingAPIs []string `json:"missingAPIs,omitempty"`
+}
+
+// OverridePatchType is the format of an override patch
+// +kubebuilder:validation:Enum=StrategicMerge;Merge;JSON
+type OverridePatchType string
+
+const (
+	// StrategicMergePatch is a Kubernetes strategic merge patch
+	StrategicMergePatch OverridePatchType = "StrategicMerge"
+	// MergePatch is a JSON merge patch (RFC 7386)
+	MergePatch OverridePatchType = "Merge"
+	// JSONPatch is a JSON patch (RFC 6902)
+	JSONPatch OverridePatchType = "JSON"
+)
+
+// Override is a patch applied to a single rendered resource
+type Override struct {
+	// Group of the target resource, empty for the core API group
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Kind of the target resource
+	Kind string `json:"kind"`
+
+	// Name of the target resource
+	Name string `json:"name"`
+
+	// Type of the patch, StrategicMerge by default
+	// +optional
+	Type OverridePatchType `json:"type,omitempty"`
+
+	// Patch in YAML or JSON
+	Patch string `json:"patch"`
+}
+
+// OverrideError reports an override that could not be applied
+type OverrideError struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+
+	// Error describes why the override was rejected
+	Error string `json:"error"`
+}
+
+// DriftIgnoreError reports a drift ignore path that was skipped
+type DriftIgnoreError struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+	Path string `json:"path"`
+
+	// Error describes why the path was rejected
+	Error string `json:"error"`
+}
+
+// ResourceReference identifies a managed resource
+type ResourceReference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ResourceDrift describes the fields of a managed resource that drifted
+type ResourceDrift struct {
+	ResourceReference `json:",inline"`
+
+	// Fields are the paths of the drifted fields
+	Fields []string `json:"fields"`
 }
 
 //+kubebuilder:object:root=true
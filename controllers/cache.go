@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// installNamespaceKinds are the kinds the operator only has namespaced
+// permissions for, see the +kubebuilder:rbac markers limited to the install
+// namespace. They are only listed and watched in the install namespace.
+var installNamespaceKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Secret"}:                               true,
+	{Group: "", Kind: "ConfigMap"}:                            true,
+	{Group: "", Kind: "Service"}:                              true,
+	{Group: "", Kind: "ServiceAccount"}:                       true,
+	{Group: "apps", Kind: "Deployment"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"}:        true,
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}: true,
+	{Group: "networking.k8s.io", Kind: "Ingress"}:             true,
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"}:       true,
+	{Group: "route.openshift.io", Kind: "Route"}:              true,
+	{Group: certificateGVK.Group, Kind: certificateGVK.Kind}:  true,
+}
+
+// NewCache is the cache.NewCacheFunc of the manager. Objects of the
+// installNamespaceKinds are cached from the install namespace only, every
+// other object cluster wide.
+func NewCache(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+	clusterCache, err := cache.New(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Namespace = InstallNamespace
+	namespacedCache, err := cache.New(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &installNamespaceCache{Cache: clusterCache, namespaced: namespacedCache, scheme: opts.Scheme}, nil
+}
+
+// installNamespaceCache sends the reads and informers of the
+// installNamespaceKinds to the namespaced cache.
+type installNamespaceCache struct {
+	cache.Cache
+	namespaced cache.Cache
+	scheme     *runtime.Scheme
+}
+
+var _ cache.Cache = &installNamespaceCache{}
+
+// cacheFor returns the cache holding objects of the GVK, which can be the one
+// of a list.
+func (c *installNamespaceCache) cacheFor(gvk schema.GroupVersionKind) cache.Cache {
+	if installNamespaceKinds[schema.GroupKind{Group: gvk.Group, Kind: strings.TrimSuffix(gvk.Kind, "List")}] {
+		return c.namespaced
+	}
+	return c.Cache
+}
+
+func (c *installNamespaceCache) cacheForObject(obj runtime.Object) cache.Cache {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		// The cluster cache reports the error
+		return c.Cache
+	}
+	return c.cacheFor(gvk)
+}
+
+func (c *installNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.cacheForObject(obj).Get(ctx, key, obj)
+}
+
+func (c *installNamespaceCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.cacheForObject(list).List(ctx, list, opts...)
+}
+
+func (c *installNamespaceCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	return c.cacheForObject(obj).GetInformer(ctx, obj)
+}
+
+func (c *installNamespaceCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.cacheFor(gvk).GetInformerForKind(ctx, gvk)
+}
+
+func (c *installNamespaceCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	return c.cacheForObject(obj).IndexField(ctx, obj, field, extractValue)
+}
+
+// Start runs both caches until the context is closed.
+func (c *installNamespaceCache) Start(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.namespaced.Start(ctx)
+	}()
+	if err := c.Cache.Start(ctx); err != nil {
+		return err
+	}
+	return <-errs
+}
+
+func (c *installNamespaceCache) WaitForCacheSync(ctx context.Context) bool {
+	return c.Cache.WaitForCacheSync(ctx) && c.namespaced.WaitForCacheSync(ctx)
+}
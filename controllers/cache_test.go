@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+var _ = Describe("Cache", func() {
+	var clusterCache, namespacedCache *informertest.FakeInformers
+	var c *installNamespaceCache
+
+	BeforeEach(func() {
+		clusterCache = &informertest.FakeInformers{}
+		namespacedCache = &informertest.FakeInformers{}
+		c = &installNamespaceCache{Cache: clusterCache, namespaced: namespacedCache, scheme: scheme.Scheme}
+	})
+
+	It("caches Secrets from the install namespace only", func() {
+		Expect(c.cacheForObject(&corev1.Secret{})).To(BeIdenticalTo(namespacedCache))
+		Expect(c.cacheForObject(&corev1.SecretList{})).To(BeIdenticalTo(namespacedCache))
+		Expect(c.cacheForObject(&appsv1.Deployment{})).To(BeIdenticalTo(namespacedCache))
+
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(certificateGVK)
+		Expect(c.cacheForObject(cert)).To(BeIdenticalTo(namespacedCache))
+	})
+
+	It("caches the other objects cluster wide", func() {
+		Expect(c.cacheForObject(&corev1.Namespace{})).To(BeIdenticalTo(clusterCache))
+		Expect(c.cacheForObject(&pipelinev1beta1.TaskList{})).To(BeIdenticalTo(clusterCache))
+		Expect(c.cacheForObject(&cranev1alpha1.OperatorConfig{})).To(BeIdenticalTo(clusterCache))
+	})
+})
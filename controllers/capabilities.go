@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1alpha1 "github.com/openshift/api/console/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Capabilities are the optional APIs served by the cluster
+type Capabilities struct {
+	// ConsolePlugin is true when console.openshift.io ConsolePlugins are served
+	ConsolePlugin bool
+	// ConsolePluginV1 is true when the v1 version of ConsolePlugins is served,
+	// which replaces v1alpha1
+	ConsolePluginV1 bool
+	// ClusterTask is true when tekton.dev ClusterTasks are served
+	ClusterTask bool
+	// Route is true when route.openshift.io Routes are served
+	Route bool
+	// OpenShift is true when config.openshift.io ClusterVersions are served
+	OpenShift bool
+}
+
+var routeGVK = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+// The openshift/api version in use only has types for v1alpha1 ConsolePlugins,
+// v1 ConsolePlugins are handled as unstructured objects.
+var (
+	consolePluginV1Alpha1GVK = consolev1alpha1.GroupVersion.WithKind("ConsolePlugin")
+	consolePluginV1GVK       = schema.GroupVersionKind{Group: "console.openshift.io", Version: "v1", Kind: "ConsolePlugin"}
+)
+
+// detectCapabilities looks up the optional APIs in the REST mapper of the
+// client.
+func (r *OperatorConfigReconciler) detectCapabilities() Capabilities {
+	mapper := r.restMapper()
+	return Capabilities{
+		ConsolePlugin:   served(mapper, consolePluginV1Alpha1GVK) || served(mapper, consolePluginV1GVK),
+		ConsolePluginV1: served(mapper, consolePluginV1GVK),
+		ClusterTask:     served(mapper, pipelinev1beta1.SchemeGroupVersion.WithKind("ClusterTask")),
+		Route:           served(mapper, routeGVK),
+		OpenShift:       served(mapper, configv1.GroupVersion.WithKind("ClusterVersion")),
+	}
+}
+
+func served(mapper meta.RESTMapper, gvk schema.GroupVersionKind) bool {
+	if mapper == nil {
+		return false
+	}
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
@@ -0,0 +1,425 @@
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// CASecretName is the Secret holding the CA signing the serving
+	// certificates generated by the operator
+	CASecretName = "crane-operator-ca"
+
+	caLifetime          = 2 * 365 * 24 * time.Hour
+	servingCertLifetime = 365 * 24 * time.Hour
+	// clockSkew backdates the generated certificates
+	clockSkew = time.Hour
+
+	// previousCAKey of the CA Secret holds the CA certificate replaced by
+	// the last renewal
+	previousCAKey = "ca-previous.crt"
+	// caRotationOverlap is how long the serving certificates signed by the
+	// previous CA are kept after a CA renewal, so the new CA reaches the
+	// ca.crt of the clients before the servers present certificates it
+	// signed
+	caRotationOverlap = 10 * time.Minute
+
+	// serviceCAAnnotation is set by the OpenShift service CA on the Secrets
+	// it writes the serving certificates to
+	serviceCAAnnotation = "service.beta.openshift.io/originating-service-name"
+
+	// certManagerAnnotation is set by cert-manager on the Secrets it writes
+	// the issued certificates to
+	certManagerAnnotation = "cert-manager.io/certificate-name"
+)
+
+// servingCert is a serving certificate of an operand Service
+type servingCert struct {
+	service string
+	secret  string
+}
+
+// servingCerts are the serving certificates the service-ca operator provides
+// on OpenShift, and the operator generates otherwise.
+var servingCerts = []servingCert{
+	{service: "proxy", secret: "crane-reverse-proxy-certs"},
+	{service: "secret-service", secret: "crane-secret-service-certs"},
+}
+
+// keyPair is a parsed certificate with its private key
+type keyPair struct {
+	cert    *x509.Certificate
+	key     crypto.Signer
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// certificateProviderFor returns the certificate provider set in the spec, or
+// the default one of the platform.
+func certificateProviderFor(oc *cranev1alpha1.OperatorConfig, platform cranev1alpha1.Platform) cranev1alpha1.CertificateProvider {
+	if oc.Spec.Certificates != nil && oc.Spec.Certificates.Provider != "" && oc.Spec.Certificates.Provider != cranev1alpha1.CertificateProviderAuto {
+		return oc.Spec.Certificates.Provider
+	}
+	if platform == cranev1alpha1.PlatformOpenShift {
+		return cranev1alpha1.CertificateProviderServiceCA
+	}
+	return cranev1alpha1.CertificateProviderSelfSigned
+}
+
+// reconcileServingCertificates provides the serving certificates of the
+// operand Services with the configured provider. The service CA needs nothing
+// but the annotations in the manifests. It returns how long to wait before
+// the certificates have to be checked again.
+func (r *OperatorConfigReconciler) reconcileServingCertificates(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) (time.Duration, error) {
+	switch certificateProviderFor(oc, oc.Status.Platform) {
+	case cranev1alpha1.CertificateProviderSelfSigned:
+		return r.reconcileCertificates(ctx, log, oc)
+	case cranev1alpha1.CertificateProviderCertManager:
+		err := r.reconcileCertManagerCertificates(ctx, log, oc)
+		if _, ok := err.(certificatesNotReadyError); ok {
+			return certManagerRecheck, err
+		}
+		return 0, err
+	}
+	return 0, nil
+}
+
+// pruneCertificates removes what a previous certificate provider left behind:
+// the cert-manager Certificates when the provider is not CertManager, and the
+// generated CA and serving certificates when it is not SelfSigned, so the
+// current provider can write the serving certificate Secrets.
+func (r *OperatorConfigReconciler) pruneCertificates(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	provider := certificateProviderFor(oc, oc.Status.Platform)
+	if provider != cranev1alpha1.CertificateProviderCertManager && served(r.restMapper(), certificateGVK) {
+		for _, s := range servingCerts {
+			cert := &unstructured.Unstructured{}
+			cert.SetGroupVersionKind(certificateGVK)
+			err := r.Get(ctx, types.NamespacedName{Name: s.secret, Namespace: InstallNamespace}, cert)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !metav1.IsControlledBy(cert, oc) {
+				continue
+			}
+			unmanaged, err := r.unmanaged(oc, cert)
+			if err != nil {
+				return err
+			}
+			if unmanaged {
+				continue
+			}
+			err = r.Delete(ctx, cert)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			log.Info("Removed Certificate of the previous certificate provider", "name", cert.GetName())
+		}
+	}
+	if provider == cranev1alpha1.CertificateProviderSelfSigned {
+		return nil
+	}
+
+	names := []string{CASecretName}
+	for _, s := range servingCerts {
+		names = append(names, s.secret)
+	}
+	for _, name := range names {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: InstallNamespace}, secret)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !staleCertificateSecret(secret, oc, provider) {
+			continue
+		}
+		unmanaged, err := r.unmanaged(oc, secret)
+		if err != nil {
+			return err
+		}
+		if unmanaged {
+			continue
+		}
+		err = r.Delete(ctx, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Removed certificate Secret of the previous certificate provider", "name", secret.Name)
+	}
+	return nil
+}
+
+// staleCertificateSecret returns true when the Secret was not written by the
+// current certificate provider. Secrets are only considered when the operator
+// generated them or cert-manager issued them.
+func staleCertificateSecret(secret *corev1.Secret, oc *cranev1alpha1.OperatorConfig, provider cranev1alpha1.CertificateProvider) bool {
+	issuedByCertManager := secret.Annotations[certManagerAnnotation] != ""
+	switch {
+	case secret.Annotations[serviceCAAnnotation] != "":
+		return false
+	case issuedByCertManager:
+		return provider != cranev1alpha1.CertificateProviderCertManager
+	}
+	return metav1.IsControlledBy(secret, oc)
+}
+
+// reconcileCertificates generates a self-signed CA and the serving
+// certificates of the operand Services. Certificates are regenerated once
+// two thirds of their lifetime passed, the expiry of each is recorded in the
+// status. It returns how long to wait until the next renewal.
+//
+// A renewed CA is first added to the ca.crt of the serving certificate
+// Secrets next to the previous one, which stays trusted until it expires.
+// The serving certificates signed by the previous CA are only replaced once
+// caRotationOverlap passed, so clients never see certificates signed by a CA
+// they do not trust yet.
+func (r *OperatorConfigReconciler) reconcileCertificates(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) (time.Duration, error) {
+	now := time.Now()
+	ca, caSecret, err := r.ensureKeyPair(ctx, log, oc, CASecretName, nil,
+		func(cert *x509.Certificate) bool {
+			return cert.IsCA && !needsRenewal(cert, now)
+		},
+		func() (*keyPair, error) {
+			return generateCA(now)
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	renewal := renewalTime(ca.cert)
+	trusted := ca.certPEM
+	previous, previousPEM := previousCA(caSecret, now)
+	overlapEnd := ca.cert.NotBefore.Add(clockSkew + caRotationOverlap)
+	if previous != nil {
+		trusted = append(append([]byte{}, ca.certPEM...), previousPEM...)
+		if now.Before(overlapEnd) && overlapEnd.Before(renewal) {
+			renewal = overlapEnd
+		}
+	}
+
+	for _, s := range servingCerts {
+		dnsNames := serviceDNSNames(s.service, InstallNamespace)
+		pair, _, err := r.ensureKeyPair(ctx, log, oc, s.secret, trusted,
+			func(cert *x509.Certificate) bool {
+				signed := cert.CheckSignatureFrom(ca.cert) == nil ||
+					(previous != nil && now.Before(overlapEnd) && cert.CheckSignatureFrom(previous) == nil)
+				return signed && !needsRenewal(cert, now) && cert.VerifyHostname(dnsNames[0]) == nil
+			},
+			func() (*keyPair, error) {
+				return generateServingCert(ca, dnsNames, now)
+			})
+		if err != nil {
+			return 0, err
+		}
+		if renewalTime(pair.cert).Before(renewal) {
+			renewal = renewalTime(pair.cert)
+		}
+	}
+	return renewal.Sub(now), nil
+}
+
+// previousCA returns the CA certificate replaced by the last renewal of the
+// CA, unless it expired.
+func previousCA(caSecret *corev1.Secret, now time.Time) (*x509.Certificate, []byte) {
+	certPEM := caSecret.Data[previousCAKey]
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || !now.Before(cert.NotAfter) {
+		return nil, nil
+	}
+	return cert, certPEM
+}
+
+// ensureKeyPair keeps the key pair in the named TLS Secret valid, generating
+// a new one when it is missing or no longer valid. The CA bundle, if any, is
+// stored in the ca.crt key. Without a CA bundle the key pair is the CA, which
+// keeps the certificate it replaces in the previousCAKey.
+func (r *OperatorConfigReconciler) ensureKeyPair(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig, name string, caBundle []byte, valid func(*x509.Certificate) bool, generate func() (*keyPair, error)) (*keyPair, *corev1.Secret, error) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: InstallNamespace}}
+
+	var pair *keyPair
+	// The drift policy does not apply, rotated certificates are always
+	// written.
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, secret, func() error {
+		err := controllerutil.SetControllerReference(oc, secret, r.Scheme)
+		if err != nil {
+			return err
+		}
+		secret.Type = corev1.SecretTypeTLS
+		if caBundle != nil {
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+			secret.Data["ca.crt"] = caBundle
+		}
+
+		current, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err == nil && valid(current.cert) {
+			pair = current
+			return nil
+		}
+
+		pair, err = generate()
+		if err != nil {
+			return err
+		}
+		data := map[string][]byte{
+			corev1.TLSCertKey:       pair.certPEM,
+			corev1.TLSPrivateKeyKey: pair.keyPEM,
+		}
+		if caBundle != nil {
+			data["ca.crt"] = caBundle
+		} else if current != nil && current.cert.IsCA && time.Now().Before(current.cert.NotAfter) {
+			data[previousCAKey] = current.certPEM
+		}
+		secret.Data = data
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Info("Certificate successfully reconciled", "secret", name, "operation", op)
+
+	recordCertificate(&oc.Status, cranev1alpha1.CertificateStatus{
+		SecretName: name,
+		NotAfter:   metav1.NewTime(pair.cert.NotAfter),
+	})
+	return pair, secret, nil
+}
+
+// needsRenewal returns true once two thirds of the lifetime of the
+// certificate passed.
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	return now.After(renewalTime(cert))
+}
+
+func renewalTime(cert *x509.Certificate) time.Time {
+	return cert.NotBefore.Add(cert.NotAfter.Sub(cert.NotBefore) * 2 / 3)
+}
+
+// serviceDNSNames returns the DNS names a Service is reached by.
+func serviceDNSNames(service, namespace string) []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+		fmt.Sprintf("%s.%s", service, namespace),
+		service,
+	}
+}
+
+func generateCA(now time.Time) (*keyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("crane-operator-ca@%d", now.Unix())},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(caLifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return generateKeyPair(template, nil)
+}
+
+func generateServingCert(ca *keyPair, dnsNames []string, now time.Time) (*keyPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-clockSkew),
+		NotAfter:    now.Add(servingCertLifetime),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return generateKeyPair(template, ca)
+}
+
+// generateKeyPair creates a certificate from the template signed by the CA,
+// or self-signed when the CA is nil.
+func generateKeyPair(template *x509.Certificate, ca *keyPair) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	parent, signer := template, crypto.Signer(key)
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseKeyPair(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	)
+}
+
+func parseKeyPair(certPEM, keyPEM []byte) (*keyPair, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("no private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return &keyPair{cert: cert, key: signer, certPEM: certPEM, keyPEM: keyPEM}, nil
+}
+
+// recordCertificate adds the certificate to the status, keeping the list
+// sorted so the status does not change between reconciles.
+func recordCertificate(status *cranev1alpha1.OperatorConfigStatus, cert cranev1alpha1.CertificateStatus) {
+	status.Certificates = append(status.Certificates, cert)
+	sort.Slice(status.Certificates, func(i, j int) bool {
+		return status.Certificates[i].SecretName < status.Certificates[j].SecretName
+	})
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Certificates", func() {
+	var r *OperatorConfigReconciler
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc = &cranev1alpha1.OperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: OwnerConfigName,
+				UID:  "test",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		for _, name := range []string{CASecretName, servingCerts[0].secret, servingCerts[1].secret} {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: InstallNamespace}}
+			Expect(client.IgnoreNotFound(c.Delete(context.TODO(), secret))).To(Succeed())
+		}
+	})
+
+	servingPair := func() *keyPair {
+		secret := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: servingCerts[0].secret, Namespace: InstallNamespace}, secret)).To(Succeed())
+		pair, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+
+		roots := x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(secret.Data["ca.crt"])).To(BeTrue())
+		_, err = pair.cert.Verify(x509.VerifyOptions{DNSName: "proxy." + InstallNamespace + ".svc", Roots: roots})
+		Expect(err).NotTo(HaveOccurred())
+		return pair
+	}
+
+	It("generates serving certificates signed by the CA", func() {
+		renewal, err := r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(renewal).To(BeNumerically(">", 200*24*time.Hour))
+
+		servingPair()
+		Expect(oc.Status.Certificates).To(HaveLen(3))
+		Expect(oc.Status.Certificates[0].SecretName).To(Equal(CASecretName))
+	})
+
+	It("keeps valid certificates", func() {
+		_, err := r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).NotTo(HaveOccurred())
+		first := servingPair()
+
+		oc.Status.Certificates = nil
+		_, err = r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(servingPair().cert.SerialNumber).To(Equal(first.cert.SerialNumber))
+	})
+
+	It("renews certificates close to their expiry", func() {
+		now := time.Now()
+		ca, err := generateCA(now)
+		Expect(err).NotTo(HaveOccurred())
+		pair, err := generateServingCert(ca, serviceDNSNames("proxy", InstallNamespace), now.Add(-300*24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(needsRenewal(ca.cert, now)).To(BeFalse())
+		Expect(needsRenewal(pair.cert, now)).To(BeTrue())
+	})
+
+	Context("when the CA is renewed", func() {
+		var oldCA *keyPair
+		var oldSerial string
+
+		writeSecret := func(name string, pair *keyPair, data map[string][]byte) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: InstallNamespace},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: pair.certPEM, corev1.TLSPrivateKeyKey: pair.keyPEM},
+			}
+			for key, value := range data {
+				secret.Data[key] = value
+			}
+			Expect(controllerutil.SetControllerReference(oc, secret, scheme.Scheme)).To(Succeed())
+			Expect(c.Create(context.TODO(), secret)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			now := time.Now()
+			var err error
+			oldCA, err = generateCA(now.Add(-500 * 24 * time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			for _, s := range servingCerts {
+				pair, err := generateServingCert(oldCA, serviceDNSNames(s.service, InstallNamespace), now)
+				Expect(err).NotTo(HaveOccurred())
+				writeSecret(s.secret, pair, map[string][]byte{"ca.crt": oldCA.certPEM})
+				if s == servingCerts[0] {
+					oldSerial = pair.cert.SerialNumber.String()
+				}
+			}
+		})
+
+		It("trusts both CAs before replacing the serving certificates", func() {
+			writeSecret(CASecretName, oldCA, nil)
+
+			renewal, err := r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renewal).To(BeNumerically("<=", caRotationOverlap))
+
+			caSecret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: CASecretName, Namespace: InstallNamespace}, caSecret)).To(Succeed())
+			Expect(caSecret.Data[previousCAKey]).To(Equal(oldCA.certPEM))
+			newCA, err := parseKeyPair(caSecret.Data[corev1.TLSCertKey], caSecret.Data[corev1.TLSPrivateKeyKey])
+			Expect(err).NotTo(HaveOccurred())
+
+			pair := servingPair()
+			Expect(pair.cert.SerialNumber.String()).To(Equal(oldSerial))
+			secret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: servingCerts[0].secret, Namespace: InstallNamespace}, secret)).To(Succeed())
+			Expect(string(secret.Data["ca.crt"])).To(ContainSubstring(string(newCA.certPEM)))
+		})
+
+		It("replaces the serving certificates once the overlap passed", func() {
+			newCA, err := generateCA(time.Now().Add(-24 * time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			writeSecret(CASecretName, newCA, map[string][]byte{previousCAKey: oldCA.certPEM})
+
+			_, err = r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+			Expect(err).NotTo(HaveOccurred())
+
+			pair := servingPair()
+			Expect(pair.cert.SerialNumber.String()).NotTo(Equal(oldSerial))
+			Expect(pair.cert.CheckSignatureFrom(newCA.cert)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: servingCerts[0].secret, Namespace: InstallNamespace}, secret)).To(Succeed())
+			Expect(string(secret.Data["ca.crt"])).To(ContainSubstring(string(oldCA.certPEM)))
+		})
+	})
+
+	It("removes the generated certificates once the provider changed", func() {
+		_, err := r.reconcileCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Still the provider
+		oc.Status.Platform = cranev1alpha1.PlatformKubernetes
+		Expect(r.pruneCertificates(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		servingPair()
+
+		// The service CA already took over the second serving certificate
+		written := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: servingCerts[1].secret, Namespace: InstallNamespace}, written)).To(Succeed())
+		written.Annotations = map[string]string{serviceCAAnnotation: servingCerts[1].service}
+		Expect(c.Update(context.TODO(), written)).To(Succeed())
+
+		oc.Status.Platform = cranev1alpha1.PlatformOpenShift
+		Expect(r.pruneCertificates(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		for _, name := range []string{CASecretName, servingCerts[0].secret} {
+			err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: InstallNamespace}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), name)
+		}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(written), &corev1.Secret{})).To(Succeed())
+	})
+
+	It("tells apart the Secrets of each provider", func() {
+		generated := &corev1.Secret{}
+		Expect(controllerutil.SetControllerReference(oc, generated, scheme.Scheme)).To(Succeed())
+		issued := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{certManagerAnnotation: "crane-reverse-proxy-certs"}}}
+		foreign := &corev1.Secret{}
+
+		Expect(staleCertificateSecret(generated, oc, cranev1alpha1.CertificateProviderServiceCA)).To(BeTrue())
+		Expect(staleCertificateSecret(generated, oc, cranev1alpha1.CertificateProviderCertManager)).To(BeTrue())
+		Expect(staleCertificateSecret(issued, oc, cranev1alpha1.CertificateProviderServiceCA)).To(BeTrue())
+		Expect(staleCertificateSecret(issued, oc, cranev1alpha1.CertificateProviderCertManager)).To(BeFalse())
+		Expect(staleCertificateSecret(foreign, oc, cranev1alpha1.CertificateProviderServiceCA)).To(BeFalse())
+	})
+})
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// certManagerRecheck is how often Certificates not issued yet are checked
+// again
+const certManagerRecheck = 30 * time.Second
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certificatesNotReadyError is returned while cert-manager has not issued
+// all of the serving certificates yet.
+type certificatesNotReadyError struct {
+	secrets []string
+}
+
+func (e certificatesNotReadyError) Error() string {
+	return fmt.Sprintf("Waiting for cert-manager to issue the certificates of %s", strings.Join(e.secrets, ", "))
+}
+
+// reconcileCertManagerCertificates creates a cert-manager Certificate for
+// each of the serving certificates. It returns a certificatesNotReadyError
+// until all of them are Ready.
+func (r *OperatorConfigReconciler) reconcileCertManagerCertificates(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	if oc.Spec.Certificates == nil || oc.Spec.Certificates.IssuerRef == nil {
+		return fmt.Errorf("spec.certificates.issuerRef is required by the CertManager certificate provider")
+	}
+	if !served(r.restMapper(), certificateGVK) {
+		return fmt.Errorf("cert-manager Certificates are not served by the cluster, install cert-manager or change spec.certificates.provider")
+	}
+	issuerRef := issuerReference(*oc.Spec.Certificates.IssuerRef)
+
+	var notReady []string
+	for _, s := range servingCerts {
+		var dnsNames []interface{}
+		for _, name := range serviceDNSNames(s.service, InstallNamespace) {
+			dnsNames = append(dnsNames, name)
+		}
+
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(certificateGVK)
+		cert.SetName(s.secret)
+		cert.SetNamespace(InstallNamespace)
+		op, err := r.createOrPatch(ctx, oc, cert, func() error {
+			err := controllerutil.SetControllerReference(oc, cert, r.Scheme)
+			if err != nil {
+				return err
+			}
+			return unstructured.SetNestedField(cert.Object, map[string]interface{}{
+				"secretName": s.secret,
+				"dnsNames":   dnsNames,
+				"usages":     []interface{}{"server auth", "digital signature", "key encipherment"},
+				"issuerRef":  issuerRef,
+			}, "spec")
+		})
+		if err != nil {
+			return err
+		}
+		log.Info("Certificate successfully reconciled", "certificate", s.secret, "operation", op)
+
+		if !certificateReady(cert) {
+			notReady = append(notReady, s.secret)
+			continue
+		}
+		notAfter, _, _ := unstructured.NestedString(cert.Object, "status", "notAfter")
+		if t, err := time.Parse(time.RFC3339, notAfter); err == nil {
+			recordCertificate(&oc.Status, cranev1alpha1.CertificateStatus{
+				SecretName: s.secret,
+				NotAfter:   metav1.NewTime(t),
+			})
+		}
+	}
+
+	if len(notReady) > 0 {
+		return certificatesNotReadyError{secrets: notReady}
+	}
+	return nil
+}
+
+// issuerReference returns the issuerRef of a Certificate with the defaults
+// applied.
+func issuerReference(ref cranev1alpha1.IssuerReference) map[string]interface{} {
+	if ref.Kind == "" {
+		ref.Kind = "Issuer"
+	}
+	if ref.Group == "" {
+		ref.Group = certificateGVK.Group
+	}
+	return map[string]interface{}{
+		"name":  ref.Name,
+		"kind":  ref.Kind,
+		"group": ref.Group,
+	}
+}
+
+// certificateReady returns true when the Ready condition of the Certificate
+// is True.
+func certificateReady(cert *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if ok && condition["type"] == "Ready" {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("cert-manager", func() {
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{}
+	})
+
+	It("defaults the provider to the platform", func() {
+		Expect(certificateProviderFor(oc, cranev1alpha1.PlatformOpenShift)).To(Equal(cranev1alpha1.CertificateProviderServiceCA))
+		Expect(certificateProviderFor(oc, cranev1alpha1.PlatformKubernetes)).To(Equal(cranev1alpha1.CertificateProviderSelfSigned))
+
+		oc.Spec.Certificates = &cranev1alpha1.CertificatesConfig{Provider: cranev1alpha1.CertificateProviderCertManager}
+		Expect(certificateProviderFor(oc, cranev1alpha1.PlatformOpenShift)).To(Equal(cranev1alpha1.CertificateProviderCertManager))
+	})
+
+	It("requires an issuer", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc.Spec.Certificates = &cranev1alpha1.CertificatesConfig{Provider: cranev1alpha1.CertificateProviderCertManager}
+
+		err := r.reconcileCertManagerCertificates(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).To(MatchError(ContainSubstring("issuerRef")))
+	})
+
+	It("defaults the kind and group of the issuer", func() {
+		Expect(issuerReference(cranev1alpha1.IssuerReference{Name: "ca-issuer"})).To(Equal(map[string]interface{}{
+			"name":  "ca-issuer",
+			"kind":  "Issuer",
+			"group": "cert-manager.io",
+		}))
+	})
+
+	It("reads the Ready condition of Certificates", func() {
+		cert := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		Expect(certificateReady(cert)).To(BeFalse())
+
+		Expect(unstructured.SetNestedSlice(cert.Object, []interface{}{
+			map[string]interface{}{"type": "Issuing", "status": "True"},
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		Expect(certificateReady(cert)).To(BeTrue())
+	})
+})
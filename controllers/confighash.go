@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigHashAnnotation on the pod template of an operand Deployment holds a
+// hash of the ConfigMaps and Secrets its pods reference, so changes to them
+// roll out new pods.
+const ConfigHashAnnotation = "crane.konveyor.io/config-hash"
+
+// configHash returns a hash of the content of the ConfigMaps and Secrets
+// referenced by the pod spec. Missing objects are hashed as such.
+func (r *OperatorConfigReconciler) configHash(ctx context.Context, namespace string, podSpec *corev1.PodSpec) (string, error) {
+	configMaps, secrets := podReferences(podSpec)
+
+	content := map[string]interface{}{}
+	for _, name := range configMaps {
+		configMap := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+		content["configmap/"+name] = []interface{}{configMap.Data, configMap.BinaryData}
+	}
+	for _, name := range secrets {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+		content["secret/"+name] = secret.Data
+	}
+
+	// Maps are encoded with sorted keys
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// podReferences returns the sorted names of the ConfigMaps and Secrets
+// referenced by the volumes and env vars of the pod spec.
+func podReferences(podSpec *corev1.PodSpec) ([]string, []string) {
+	configMaps, secrets := map[string]bool{}, map[string]bool{}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.ConfigMap != nil {
+			configMaps[volume.ConfigMap.Name] = true
+		}
+		if volume.Secret != nil {
+			secrets[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					configMaps[source.ConfigMap.Name] = true
+				}
+				if source.Secret != nil {
+					secrets[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, container := range containers {
+			for _, env := range container.Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if env.ValueFrom.ConfigMapKeyRef != nil {
+					configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+				}
+				if env.ValueFrom.SecretKeyRef != nil {
+					secrets[env.ValueFrom.SecretKeyRef.Name] = true
+				}
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.ConfigMapRef != nil {
+					configMaps[envFrom.ConfigMapRef.Name] = true
+				}
+				if envFrom.SecretRef != nil {
+					secrets[envFrom.SecretRef.Name] = true
+				}
+			}
+		}
+	}
+
+	return sortedKeys(configMaps), sortedKeys(secrets)
+}
+
+// servingCertSecret matches the Secrets the OpenShift service CA or
+// cert-manager write the serving certificates of the install namespace to,
+// they are not owned by the OperatorConfig.
+func servingCertSecret(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	return obj.GetNamespace() == InstallNamespace &&
+		(annotations[serviceCAAnnotation] != "" || annotations[certManagerAnnotation] != "")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
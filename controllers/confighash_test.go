@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Config hash", func() {
+	var r *OperatorConfigReconciler
+	var configMap *corev1.ConfigMap
+	var podSpec *corev1.PodSpec
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("nginx-conf-%d", rand.Int31()), //nolint:gosec
+				Namespace: "default",
+			},
+			Data: map[string]string{"nginx.conf": "events {}"},
+		}
+		podSpec = &corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "conf", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMap.Name},
+				}}},
+				{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}},
+			},
+			Containers: []corev1.Container{{
+				Name: "busybox",
+				EnvFrom: []corev1.EnvFromSource{
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}},
+				},
+			}},
+		}
+	})
+
+	It("finds the referenced ConfigMaps and Secrets", func() {
+		configMaps, secrets := podReferences(podSpec)
+		Expect(configMaps).To(Equal([]string{configMap.Name}))
+		Expect(secrets).To(Equal([]string{"certs", "credentials"}))
+	})
+
+	It("changes with the referenced content", func() {
+		missing, err := r.configHash(context.TODO(), "default", podSpec)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Create(context.TODO(), configMap)).To(Succeed())
+		created, err := r.configHash(context.TODO(), "default", podSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).NotTo(Equal(missing))
+
+		again, err := r.configHash(context.TODO(), "default", podSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(created))
+
+		configMap.Data["nginx.conf"] = "events {}\nhttp {}"
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+		updated, err := r.configHash(context.TODO(), "default", podSpec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).NotTo(Equal(created))
+	})
+
+	It("matches the serving certificate Secrets of the install namespace", func() {
+		secret := func(namespace string, annotations map[string]string) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "crane-proxy-certs", Namespace: namespace, Annotations: annotations}}
+		}
+
+		Expect(servingCertSecret(secret(InstallNamespace, map[string]string{serviceCAAnnotation: "crane-proxy"}))).To(BeTrue())
+		Expect(servingCertSecret(secret(InstallNamespace, map[string]string{certManagerAnnotation: "crane-proxy"}))).To(BeTrue())
+		Expect(servingCertSecret(secret(InstallNamespace, nil))).To(BeFalse())
+		Expect(servingCertSecret(secret("default", map[string]string{certManagerAnnotation: "crane-proxy"}))).To(BeFalse())
+	})
+})
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ConsolePluginName is the name of the ConsolePlugin of crane-ui-plugin
+	ConsolePluginName = "crane-ui-plugin"
+
+	clusterConsoleName = "cluster"
+)
+
+var consoleGVK = operatorv1.GroupVersion.WithKind("Console")
+
+// reconcileConsolePluginEnabled adds the crane-ui-plugin to the plugins loaded
+// by the console, or removes it, as set by spec.enableConsolePlugin. Whether
+// the plugin is loaded is recorded in the status either way.
+func (r *OperatorConfigReconciler) reconcileConsolePluginEnabled(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	if oc.Status.Platform != cranev1alpha1.PlatformOpenShift || !served(r.restMapper(), consoleGVK) {
+		return nil
+	}
+
+	console := &operatorv1.Console{}
+	err := r.Get(ctx, types.NamespacedName{Name: clusterConsoleName}, console)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	enabled := containsPlugin(console.Spec.Plugins)
+	if oc.Spec.EnableConsolePlugin != nil && *oc.Spec.EnableConsolePlugin != enabled {
+		err := r.setConsolePlugin(ctx, console, *oc.Spec.EnableConsolePlugin)
+		if err != nil {
+			return err
+		}
+		enabled = *oc.Spec.EnableConsolePlugin
+		log.Info("Console plugins updated", "plugin", ConsolePluginName, "enabled", enabled)
+	}
+	oc.Status.ConsolePluginEnabled = enabled
+	return nil
+}
+
+// disableConsolePlugin removes the crane-ui-plugin from the console if the
+// operator enabled it.
+func (r *OperatorConfigReconciler) disableConsolePlugin(ctx context.Context, oc *cranev1alpha1.OperatorConfig) error {
+	if oc.Spec.EnableConsolePlugin == nil || !*oc.Spec.EnableConsolePlugin || !served(r.restMapper(), consoleGVK) {
+		return nil
+	}
+
+	console := &operatorv1.Console{}
+	err := r.Get(ctx, types.NamespacedName{Name: clusterConsoleName}, console)
+	if errors.IsNotFound(err) || (err == nil && !containsPlugin(console.Spec.Plugins)) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.setConsolePlugin(ctx, console, false)
+}
+
+// setConsolePlugin adds or removes the crane-ui-plugin in the plugins of the
+// console. The list is replaced as a whole, the optimistic lock keeps plugins
+// added concurrently.
+func (r *OperatorConfigReconciler) setConsolePlugin(ctx context.Context, console *operatorv1.Console, enabled bool) error {
+	patch := client.MergeFromWithOptions(console.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	console.Spec.Plugins = pluginsWith(console.Spec.Plugins, enabled)
+	return r.Patch(ctx, console, patch)
+}
+
+// pluginsWith returns the plugins with the crane-ui-plugin added or removed,
+// keeping the order of the other plugins.
+func pluginsWith(plugins []string, enabled bool) []string {
+	var updated []string
+	for _, plugin := range plugins {
+		if plugin != ConsolePluginName {
+			updated = append(updated, plugin)
+		}
+	}
+	if enabled {
+		updated = append(updated, ConsolePluginName)
+	}
+	return updated
+}
+
+func containsPlugin(plugins []string) bool {
+	for _, plugin := range plugins {
+		if plugin == ConsolePluginName {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterConsole matches the Console operator config named cluster.
+func clusterConsole(obj client.Object) bool {
+	return obj.GetName() == clusterConsoleName
+}
+
+// reconcileConsolePluginV1 reconciles a v1 ConsolePlugin, which has no typed
+// API in the vendored openshift/api.
+func (r *OperatorConfigReconciler) reconcileConsolePluginV1(resource *unstructured.Unstructured, ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	spec, _, err := unstructured.NestedMap(resource.Object, "spec")
+	if err != nil {
+		return err
+	}
+
+	consolePlugin := &unstructured.Unstructured{}
+	consolePlugin.SetGroupVersionKind(consolePluginV1GVK)
+	consolePlugin.SetName(resource.GetName())
+	op, err := r.createOrPatch(ctx, oc, consolePlugin, func() error {
+		err := controllerutil.SetControllerReference(oc, consolePlugin, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		if len(resource.GetLabels()) > 0 {
+			consolePlugin.SetLabels(resource.GetLabels())
+		}
+		if len(resource.GetAnnotations()) > 0 {
+			consolePlugin.SetAnnotations(resource.GetAnnotations())
+		}
+		return unstructured.SetNestedMap(consolePlugin.Object, spec, "spec")
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("ConsolePlugin successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Console", func() {
+	It("adds the plugin once", func() {
+		Expect(pluginsWith(nil, true)).To(Equal([]string{ConsolePluginName}))
+		Expect(pluginsWith([]string{"forklift-console-plugin", ConsolePluginName}, true)).
+			To(Equal([]string{"forklift-console-plugin", ConsolePluginName}))
+	})
+
+	It("removes the plugin and keeps the others", func() {
+		Expect(pluginsWith([]string{ConsolePluginName, "forklift-console-plugin"}, false)).
+			To(Equal([]string{"forklift-console-plugin"}))
+		Expect(pluginsWith([]string{ConsolePluginName}, false)).To(BeEmpty())
+	})
+
+	It("leaves the console alone on Kubernetes", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		enabled := true
+		oc := &cranev1alpha1.OperatorConfig{Spec: cranev1alpha1.OperatorConfigSpec{EnableConsolePlugin: &enabled}}
+		oc.Status.Platform = cranev1alpha1.PlatformKubernetes
+
+		Expect(r.reconcileConsolePluginEnabled(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(oc.Status.ConsolePluginEnabled).To(BeFalse())
+	})
+
+	Context("ConsolePlugin", func() {
+		var data manifestData
+
+		BeforeEach(func() {
+			data = manifestData{
+				Namespace: "crane-test",
+				Images:    resolvedImages(),
+				TLS:       tlsConfigFor(nil),
+				Platform:  cranev1alpha1.PlatformOpenShift,
+			}
+		})
+
+		renderConsolePlugin := func() *unstructured.Unstructured {
+			objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-ui-plugin.yaml"), data)
+			Expect(err).NotTo(HaveOccurred())
+			for _, obj := range objs {
+				if obj.GetKind() == "ConsolePlugin" {
+					return obj
+				}
+			}
+			Fail("no ConsolePlugin rendered")
+			return nil
+		}
+
+		It("renders v1alpha1 on older clusters", func() {
+			consolePlugin := renderConsolePlugin()
+			Expect(consolePlugin.GroupVersionKind()).To(Equal(consolePluginV1Alpha1GVK))
+
+			name, _, _ := unstructured.NestedString(consolePlugin.Object, "spec", "service", "name")
+			Expect(name).To(Equal("crane-ui-plugin"))
+		})
+
+		It("renders v1 with the proxy aliases translated", func() {
+			data.Capabilities.ConsolePluginV1 = true
+
+			consolePlugin := renderConsolePlugin()
+			Expect(consolePlugin.GroupVersionKind()).To(Equal(consolePluginV1GVK))
+
+			name, _, _ := unstructured.NestedString(consolePlugin.Object, "spec", "backend", "service", "name")
+			Expect(name).To(Equal("crane-ui-plugin"))
+
+			proxies, _, err := unstructured.NestedSlice(consolePlugin.Object, "spec", "proxy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proxies).To(HaveLen(2))
+			for _, p := range proxies {
+				proxy := p.(map[string]interface{})
+				service, _, _ := unstructured.NestedString(proxy, "endpoint", "service", "name")
+				switch proxy["alias"] {
+				case "remote-cluster":
+					Expect(proxy["authorization"]).To(Equal("None"))
+					Expect(service).To(Equal("proxy"))
+				case "secret-service":
+					Expect(proxy["authorization"]).To(Equal("UserToken"))
+					Expect(service).To(Equal("secret-service"))
+				default:
+					Fail("unexpected alias")
+				}
+			}
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// unpinnedImagesError is returned when spec.requireImageDigests is set and
+// some of the operand images are not pinned by digest.
+type unpinnedImagesError struct {
+	images []string
+}
+
+func (e unpinnedImagesError) Error() string {
+	return fmt.Sprintf("Images must be pinned by digest (@sha256:), not applying any resources: %s", strings.Join(e.images, ", "))
+}
+
+// checkImageDigests returns an unpinnedImagesError listing the images of the
+// rendered operands that are not pinned by digest. Images are checked after
+// the image keys and mirrors are resolved, so env var, default and spec
+// supplied images are all covered. Overrides only select image keys, the
+// ones setting images are rejected by applyOverrides.
+func checkImageDigests(rendered []renderedOperand, oc *cranev1alpha1.OperatorConfig) error {
+	if !oc.Spec.RequireImageDigests {
+		return nil
+	}
+
+	unpinned := map[string]bool{}
+	for _, o := range rendered {
+		for _, obj := range o.objs {
+			images, err := resourceImages(obj, o.imageFn, oc)
+			if err != nil {
+				return err
+			}
+			for _, image := range images {
+				if !strings.Contains(image, "@sha256:") {
+					unpinned[image] = true
+				}
+			}
+		}
+	}
+	if len(unpinned) == 0 {
+		return nil
+	}
+
+	err := unpinnedImagesError{}
+	for image := range unpinned {
+		err.images = append(err.images, image)
+	}
+	sort.Strings(err.images)
+	return err
+}
+
+// resourceImages returns the images the containers of a rendered Deployment
+// or the steps of a rendered ClusterTask or Task are run with.
+func resourceImages(resource *unstructured.Unstructured, imageFn ImageFunction, oc *cranev1alpha1.OperatorConfig) ([]string, error) {
+	var images []string
+	for _, field := range containerFields(resource.GetKind()) {
+		containers, _, err := unstructured.NestedSlice(resource.Object, field...)
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			name, _, _ := unstructured.NestedString(container.(map[string]interface{}), "name")
+			image, err := imageFor(resource.GetAnnotations(), name, imageFn)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, mirrorImage(image, oc.Spec.ImageMirrors))
+		}
+	}
+	return images, nil
+}
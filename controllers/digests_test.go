@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"os"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Image digests", func() {
+	var oc *cranev1alpha1.OperatorConfig
+	var rendered []renderedOperand
+
+	pinned := "quay.io/konveyor/crane-runner@sha256:53c7ed89a431e032dbeeb5069342b963a1467647753bec6f436b5ec0dbcdce7a"
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{
+			Spec: cranev1alpha1.OperatorConfigSpec{RequireImageDigests: true},
+		}
+		task := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tekton.dev/v1beta1",
+			"kind":       "ClusterTask",
+			"metadata": map[string]interface{}{
+				"name": "crane-image-sync",
+				"annotations": map[string]interface{}{
+					ImageKeyAnnotationPrefix + "skopeo-sync": "skopeo",
+				},
+			},
+			"spec": map[string]interface{}{
+				"steps": []interface{}{
+					map[string]interface{}{"name": "crane-export"},
+					map[string]interface{}{"name": "skopeo-sync"},
+				},
+			},
+		}}
+		rendered = []renderedOperand{{
+			operand: operand{imageFn: func() string { return pinned }},
+			objs:    []*unstructured.Unstructured{task},
+		}}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("RELATED_IMAGE_SKOPEO")
+		os.Unsetenv("RELATED_IMAGE_METRICS_EXPORTER")
+	})
+
+	It("rejects images pinned by tag", func() {
+		os.Setenv("RELATED_IMAGE_SKOPEO", "quay.io/skopeo/stable:latest")
+
+		err := checkImageDigests(rendered, oc)
+		Expect(err).To(Equal(unpinnedImagesError{images: []string{"quay.io/skopeo/stable:latest"}}))
+	})
+
+	It("accepts images pinned by digest", func() {
+		os.Setenv("RELATED_IMAGE_SKOPEO", pinned)
+
+		Expect(checkImageDigests(rendered, oc)).To(Succeed())
+	})
+
+	It("checks the mirrored images", func() {
+		os.Setenv("RELATED_IMAGE_SKOPEO", pinned)
+		oc.Spec.ImageMirrors = []cranev1alpha1.ImageMirror{{Source: "quay.io/konveyor/crane-runner@sha256", Mirror: "registry.example.com/crane-runner:latest"}}
+
+		err := checkImageDigests(rendered, oc)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does nothing unless digests are required", func() {
+		oc.Spec.RequireImageDigests = false
+		os.Setenv("RELATED_IMAGE_SKOPEO", "quay.io/skopeo/stable:latest")
+
+		Expect(checkImageDigests(rendered, oc)).To(Succeed())
+	})
+
+	It("checks the images selected by overrides", func() {
+		os.Setenv("RELATED_IMAGE_SKOPEO", pinned)
+		os.Setenv("RELATED_IMAGE_METRICS_EXPORTER", "quay.io/example/metrics-exporter:latest")
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "tekton.dev",
+			Kind:  "ClusterTask",
+			Name:  "crane-image-sync",
+			Type:  cranev1alpha1.MergePatch,
+			Patch: `{"metadata": {"annotations": {"image.crane.konveyor.io/crane-export": "metrics-exporter"}}}`,
+		}, {
+			Group: "tekton.dev",
+			Kind:  "ClusterTask",
+			Name:  "crane-image-sync",
+			Type:  cranev1alpha1.JSONPatch,
+			Patch: `[{"op": "add", "path": "/spec/steps/0/image", "value": "` + pinned + `"}]`,
+		}}
+		r := &OperatorConfigReconciler{Scheme: scheme.Scheme}
+		r.applyOverrides(rendered[0].objs[0], oc)
+		Expect(oc.Status.InvalidOverrides).To(HaveLen(1))
+
+		err := checkImageDigests(rendered, oc)
+		Expect(err).To(Equal(unpinnedImagesError{images: []string{"quay.io/example/metrics-exporter:latest"}}))
+	})
+})
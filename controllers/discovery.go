@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1alpha1 "github.com/openshift/api/console/v1alpha1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// missingAPIRecheck is how often missing APIs are looked up again, in case a
+// CRD event was missed
+const missingAPIRecheck = time.Minute
+
+var (
+	clusterTaskGVK = pipelinev1beta1.SchemeGroupVersion.WithKind("ClusterTask")
+	crdGVK         = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+)
+
+// optionalWatch is a watch on an API served only on some clusters, or only
+// once another operator installed its CRD, e.g. Tekton ClusterTasks.
+type optionalWatch struct {
+	gvk schema.GroupVersionKind
+	// unless skips the watch when this API is served, it watches the same
+	// objects in another version
+	unless     *schema.GroupVersionKind
+	object     func() client.Object
+	handler    handler.EventHandler
+	predicates []predicate.Predicate
+}
+
+// optionalWatches are started as soon as their API is served.
+func optionalWatches() []optionalWatch {
+	owner := &handler.EnqueueRequestForOwner{OwnerType: &cranev1alpha1.OperatorConfig{}, IsController: true}
+	return []optionalWatch{
+		{
+			gvk:        clusterTaskGVK,
+			object:     func() client.Object { return &pipelinev1beta1.ClusterTask{} },
+			handler:    owner,
+			predicates: []predicate.Predicate{specChangedPredicate()},
+		},
+		{
+			gvk:        taskGVK,
+			object:     func() client.Object { return &pipelinev1beta1.Task{} },
+			handler:    owner,
+			predicates: []predicate.Predicate{specChangedPredicate()},
+		},
+		{
+			gvk:        consolePluginV1GVK,
+			object:     func() client.Object { return unstructuredOf(consolePluginV1GVK) },
+			handler:    owner,
+			predicates: []predicate.Predicate{specChangedPredicate()},
+		},
+		{
+			gvk:        consolePluginV1Alpha1GVK,
+			unless:     &consolePluginV1GVK,
+			object:     func() client.Object { return &consolev1alpha1.ConsolePlugin{} },
+			handler:    owner,
+			predicates: []predicate.Predicate{specChangedPredicate()},
+		},
+		{
+			gvk:        routeGVK,
+			object:     func() client.Object { return &routev1.Route{} },
+			handler:    owner,
+			predicates: []predicate.Predicate{specChangedPredicate()},
+		},
+		{
+			// cert-manager only reports the issuance and renewal of
+			// Certificates through their status
+			gvk:     certificateGVK,
+			object:  func() client.Object { return unstructuredOf(certificateGVK) },
+			handler: owner,
+			predicates: []predicate.Predicate{predicate.Or(
+				specChangedPredicate(),
+				statusChangedPredicate{fields: []string{"conditions", "notAfter"}},
+			)},
+		},
+		{
+			// The operands are configured from the cluster wide Proxy and
+			// APIServer
+			gvk:     proxyGVK,
+			object:  func() client.Object { return &configv1.Proxy{} },
+			handler: enqueueOperatorConfig(),
+		},
+		{
+			gvk:     apiServerGVK,
+			object:  func() client.Object { return &configv1.APIServer{} },
+			handler: enqueueOperatorConfig(),
+		},
+		{
+			// The crane-ui-plugin is added back when removed from the console
+			gvk:        consoleGVK,
+			object:     func() client.Object { return &operatorv1.Console{} },
+			handler:    enqueueOperatorConfig(),
+			predicates: []predicate.Predicate{predicate.NewPredicateFuncs(clusterConsole)},
+		},
+	}
+}
+
+// missingAPIMapper caches the kinds the cluster does not serve. Looking up a
+// kind the manager's RESTMapper does not know makes it reload the discovery
+// information, which is only done again for a missing kind once
+// missingAPIRecheck passed or a CRD event cleared the cache.
+type missingAPIMapper struct {
+	meta.RESTMapper
+	mu      sync.Mutex
+	missing map[schema.GroupVersionKind]time.Time
+}
+
+func newMissingAPIMapper(mapper meta.RESTMapper) *missingAPIMapper {
+	return &missingAPIMapper{RESTMapper: mapper, missing: map[schema.GroupVersionKind]time.Time{}}
+}
+
+// RESTMapping returns a NoKindMatchError without asking the RESTMapper when
+// the kind was recently found missing.
+func (m *missingAPIMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	key := gk.WithVersion(strings.Join(versions, ","))
+	m.mu.Lock()
+	checked, found := m.missing[key]
+	m.mu.Unlock()
+	if found && time.Since(checked) < missingAPIRecheck {
+		return nil, &meta.NoKindMatchError{GroupKind: gk, SearchedVersions: versions}
+	}
+
+	mapping, err := m.RESTMapper.RESTMapping(gk, versions...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if meta.IsNoMatchError(err) {
+		m.missing[key] = time.Now()
+	} else {
+		delete(m.missing, key)
+	}
+	return mapping, err
+}
+
+// forget clears the cached missing kinds, so they are looked up again.
+func (m *missingAPIMapper) forget() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.missing = map[schema.GroupVersionKind]time.Time{}
+}
+
+// enqueueOnCRD reconciles the OperatorConfig on events of the CRDs of the
+// optional APIs, after forgetting the kinds found missing so far.
+func (m *missingAPIMapper) enqueueOnCRD() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		m.forget()
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: OwnerConfigName}}}
+	})
+}
+
+// restMapper returns the RESTMapper the optional APIs are looked up with.
+func (r *OperatorConfigReconciler) restMapper() meta.RESTMapper {
+	if r.mapper != nil {
+		return r.mapper
+	}
+	return r.RESTMapper()
+}
+
+// dynamicWatches starts the optional watches of the controller once their
+// APIs are served. Starting a watch on an API that is not served fails the
+// manager.
+type dynamicWatches struct {
+	mu         sync.Mutex
+	controller controller.Controller
+	mapper     meta.RESTMapper
+	started    map[schema.GroupVersionKind]bool
+}
+
+func newDynamicWatches(c controller.Controller, mapper meta.RESTMapper) *dynamicWatches {
+	return &dynamicWatches{controller: c, mapper: mapper, started: map[schema.GroupVersionKind]bool{}}
+}
+
+// sync starts the watches of the APIs served since the last call.
+func (w *dynamicWatches) sync(log logr.Logger) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, watch := range optionalWatches() {
+		if w.started[watch.gvk] || !served(w.mapper, watch.gvk) {
+			continue
+		}
+		if watch.unless != nil && served(w.mapper, *watch.unless) {
+			continue
+		}
+		err := w.controller.Watch(&source.Kind{Type: watch.object()}, watch.handler, watch.predicates...)
+		if err != nil {
+			return err
+		}
+		w.started[watch.gvk] = true
+		log.Info("Started watch of optional API", "gvk", watch.gvk.String())
+	}
+	return nil
+}
+
+// optionalCRD matches the CustomResourceDefinitions of the groups of the
+// optional APIs, their creation starts the watches and installs the
+// operands waiting for them.
+func optionalCRD(obj client.Object) bool {
+	for _, watch := range optionalWatches() {
+		if strings.HasSuffix(obj.GetName(), "."+watch.gvk.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// crdMetadata is the type of the CustomResourceDefinition watch, only the
+// metadata is cached.
+func crdMetadata() *metav1.PartialObjectMetadata {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+func unstructuredOf(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// missingAPIs returns the APIs of the rendered objects not served by the
+// cluster, e.g. "tekton.dev/v1beta1 ClusterTask".
+func missingAPIs(mapper meta.RESTMapper, objs []*unstructured.Unstructured) ([]string, error) {
+	missing := map[string]bool{}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			missing[fmt.Sprintf("%s %s", gvk.GroupVersion(), gvk.Kind)] = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sortedKeys(missing), nil
+}
+
+// recordMissingAPIs adds the APIs to the status, keeping it sorted and free
+// of duplicates.
+func recordMissingAPIs(status *cranev1alpha1.OperatorConfigStatus, apis []string) {
+	for _, api := range apis {
+		i := sort.SearchStrings(status.MissingAPIs, api)
+		if i < len(status.MissingAPIs) && status.MissingAPIs[i] == api {
+			continue
+		}
+		status.MissingAPIs = append(status.MissingAPIs, "")
+		copy(status.MissingAPIs[i+1:], status.MissingAPIs[i:])
+		status.MissingAPIs[i] = api
+	}
+}
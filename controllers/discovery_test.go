@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// recordingController records the sources it is asked to watch.
+type recordingController struct {
+	watched []source.Source
+}
+
+func (c *recordingController) Reconcile(context.Context, reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, nil
+}
+
+func (c *recordingController) Watch(src source.Source, _ handler.EventHandler, _ ...predicate.Predicate) error {
+	c.watched = append(c.watched, src)
+	return nil
+}
+
+func (c *recordingController) Start(context.Context) error { return nil }
+
+func (c *recordingController) GetLogger() logr.Logger { return log.Log }
+
+// countingMapper counts the lookups of kinds.
+type countingMapper struct {
+	meta.RESTMapper
+	lookups int
+}
+
+func (m *countingMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	m.lookups++
+	return m.RESTMapper.RESTMapping(gk, versions...)
+}
+
+var _ = Describe("Discovery", func() {
+	var mapper *meta.DefaultRESTMapper
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	})
+
+	It("lists the APIs of rendered objects that are not served", func() {
+		deployment := unstructuredOf(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		clusterTask := unstructuredOf(clusterTaskGVK)
+
+		missing, err := missingAPIs(mapper, []*unstructured.Unstructured{deployment, clusterTask, clusterTask})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"tekton.dev/v1beta1 ClusterTask"}))
+
+		mapper.Add(clusterTaskGVK, meta.RESTScopeRoot)
+		missing, err = missingAPIs(mapper, []*unstructured.Unstructured{deployment, clusterTask})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+	})
+
+	It("records each missing API once", func() {
+		status := &cranev1alpha1.OperatorConfigStatus{}
+		recordMissingAPIs(status, []string{"tekton.dev/v1beta1 ClusterTask"})
+		recordMissingAPIs(status, []string{"console.openshift.io/v1alpha1 ConsolePlugin", "tekton.dev/v1beta1 ClusterTask"})
+		Expect(status.MissingAPIs).To(Equal([]string{"console.openshift.io/v1alpha1 ConsolePlugin", "tekton.dev/v1beta1 ClusterTask"}))
+	})
+
+	It("starts the watches of APIs once they are served", func() {
+		c := &recordingController{}
+		watches := newDynamicWatches(c, mapper)
+
+		Expect(watches.sync(log.Log)).To(Succeed())
+		Expect(c.watched).To(BeEmpty())
+
+		mapper.Add(clusterTaskGVK, meta.RESTScopeRoot)
+		mapper.Add(consolePluginV1GVK, meta.RESTScopeRoot)
+		mapper.Add(consolePluginV1Alpha1GVK, meta.RESTScopeRoot)
+		Expect(watches.sync(log.Log)).To(Succeed())
+		Expect(watches.sync(log.Log)).To(Succeed())
+
+		var kinds []schema.GroupVersionKind
+		for _, src := range c.watched {
+			kinds = append(kinds, src.(*source.Kind).Type.GetObjectKind().GroupVersionKind())
+		}
+		Expect(c.watched).To(HaveLen(2))
+		Expect(kinds).To(ContainElement(consolePluginV1GVK))
+		Expect(watches.started).To(HaveKey(clusterTaskGVK))
+		Expect(watches.started).NotTo(HaveKey(consolePluginV1Alpha1GVK))
+	})
+
+	It("caches the APIs found missing", func() {
+		counting := &countingMapper{RESTMapper: mapper}
+		cached := newMissingAPIMapper(counting)
+
+		Expect(served(cached, clusterTaskGVK)).To(BeFalse())
+		Expect(served(cached, clusterTaskGVK)).To(BeFalse())
+		Expect(counting.lookups).To(Equal(1))
+
+		// Served APIs are always looked up
+		Expect(served(cached, appsv1.SchemeGroupVersion.WithKind("Deployment"))).To(BeTrue())
+		Expect(served(cached, appsv1.SchemeGroupVersion.WithKind("Deployment"))).To(BeTrue())
+		Expect(counting.lookups).To(Equal(3))
+
+		// Looked up again once a CRD changed
+		mapper.Add(clusterTaskGVK, meta.RESTScopeRoot)
+		Expect(served(cached, clusterTaskGVK)).To(BeFalse())
+		cached.forget()
+		Expect(served(cached, clusterTaskGVK)).To(BeTrue())
+	})
+
+	It("looks missing APIs up again after a while", func() {
+		cached := newMissingAPIMapper(mapper)
+		Expect(served(cached, clusterTaskGVK)).To(BeFalse())
+
+		mapper.Add(clusterTaskGVK, meta.RESTScopeRoot)
+		for key := range cached.missing {
+			cached.missing[key] = time.Now().Add(-missingAPIRecheck)
+		}
+		Expect(served(cached, clusterTaskGVK)).To(BeTrue())
+	})
+
+	It("matches the CRDs of optional APIs", func() {
+		crd := crdMetadata()
+		crd.Name = "clustertasks.tekton.dev"
+		Expect(optionalCRD(crd)).To(BeTrue())
+
+		crd.ObjectMeta = metav1.ObjectMeta{Name: "virtualmachines.kubevirt.io"}
+		Expect(optionalCRD(crd)).To(BeFalse())
+	})
+})
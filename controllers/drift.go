@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Event reasons
+const (
+	DriftDetected      = "DriftDetected"
+	DriftCorrected     = "DriftCorrected"
+	InvalidDriftIgnore = "InvalidDriftIgnore"
+)
+
+// createOrPatch is controllerutil.CreateOrPatch with the drift policy of the
+// OperatorConfig applied to existing objects. Objects annotated with
+// crane.konveyor.io/unmanaged=true are left alone. Otherwise the mutate
+// function renders the desired state on top of the live object, the result is
+// then compared with what was found in the cluster:
+// - ignored fields are put back to their live value
+// - in Report mode the live object is left untouched and the drift recorded
+// - in Correct mode the drift is overwritten and an event emitted
+func (r *OperatorConfigReconciler) createOrPatch(ctx context.Context, oc *cranev1alpha1.OperatorConfig, obj client.Object, f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	return controllerutil.CreateOrPatch(ctx, r.Client, obj, func() error {
+		if obj.GetResourceVersion() == "" {
+			return f()
+		}
+
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		unmanaged, err := r.unmanaged(oc, obj)
+		if err != nil || unmanaged {
+			return err
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		// The content of unstructured objects is their own map, which the
+		// mutate function is about to edit.
+		live := runtime.DeepCopyJSON(content)
+		if err := f(); err != nil {
+			return err
+		}
+		desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+
+		policy := driftPolicy(oc)
+		for _, ignore := range policy.Ignore {
+			if ignore.Kind != gvk.Kind || (ignore.Name != "" && ignore.Name != obj.GetName()) {
+				continue
+			}
+			for _, path := range ignore.Paths {
+				// Invalid paths are reported by checkDriftIgnores
+				if fields, err := parseFieldPath(path); err == nil {
+					preserveField(desired, live, fields)
+				}
+			}
+		}
+
+		drifted := driftedFields(desired, live, "")
+		if len(drifted) == 0 {
+			return fromUnstructured(desired, obj)
+		}
+
+		if policy.Mode == cranev1alpha1.DriftModeReport {
+			oc.Status.Drift = append(oc.Status.Drift, cranev1alpha1.ResourceDrift{
+				ResourceReference: cranev1alpha1.ResourceReference{
+					Kind:      gvk.Kind,
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+				},
+				Fields: drifted,
+			})
+			r.event(oc, corev1.EventTypeWarning, DriftDetected, "%s %s drifted from its manifest: %s", gvk.Kind, obj.GetName(), strings.Join(drifted, ", "))
+			return fromUnstructured(live, obj)
+		}
+
+		r.event(oc, corev1.EventTypeNormal, DriftCorrected, "%s %s was reverted to its manifest: %s", gvk.Kind, obj.GetName(), strings.Join(drifted, ", "))
+		return fromUnstructured(desired, obj)
+	})
+}
+
+// fromUnstructured sets the content of obj, which can be a typed or an
+// unstructured object.
+func fromUnstructured(content map[string]interface{}, obj client.Object) error {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
+
+// driftPolicy returns the drift policy of the OperatorConfig with defaults
+// applied.
+func driftPolicy(oc *cranev1alpha1.OperatorConfig) cranev1alpha1.DriftPolicy {
+	policy := cranev1alpha1.DriftPolicy{Mode: cranev1alpha1.DriftModeCorrect}
+	if oc.Spec.Drift != nil {
+		policy.Ignore = oc.Spec.Drift.Ignore
+		if oc.Spec.Drift.Mode != "" {
+			policy.Mode = oc.Spec.Drift.Mode
+		}
+	}
+	return policy
+}
+
+// checkDriftIgnores reports the drift ignore paths of the OperatorConfig that
+// cannot be parsed. They are skipped when the resources are applied.
+func (r *OperatorConfigReconciler) checkDriftIgnores(oc *cranev1alpha1.OperatorConfig) {
+	for _, ignore := range driftPolicy(oc).Ignore {
+		for _, path := range ignore.Paths {
+			if _, err := parseFieldPath(path); err != nil {
+				oc.Status.InvalidDriftIgnores = append(oc.Status.InvalidDriftIgnores, cranev1alpha1.DriftIgnoreError{
+					Kind:  ignore.Kind,
+					Name:  ignore.Name,
+					Path:  path,
+					Error: err.Error(),
+				})
+				r.event(oc, corev1.EventTypeWarning, InvalidDriftIgnore, "Drift ignore path %q for %s was skipped: %v", path, ignore.Kind, err)
+			}
+		}
+	}
+}
+
+// fieldPath is a parsed drift ignore path.
+type fieldPath []pathSegment
+
+// pathSegment is a field of a map, optionally followed by the selector of an
+// element of the list it holds: either [<index>] or [<field>=<value>].
+type pathSegment struct {
+	field    string
+	selector bool
+	index    int
+	key      string
+	value    string
+}
+
+// parseFieldPath parses a dot separated path like
+// spec.template.spec.containers[name=proxy].env
+func parseFieldPath(path string) (fieldPath, error) {
+	var fields fieldPath
+	for _, part := range strings.Split(path, ".") {
+		segment := pathSegment{field: part}
+		if open := strings.Index(part, "["); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("unterminated list selector in %q", part)
+			}
+			segment.field = part[:open]
+			segment.selector = true
+			sel := part[open+1 : len(part)-1]
+			if key, value, ok := strings.Cut(sel, "="); ok {
+				if key == "" {
+					return nil, fmt.Errorf("missing field name in list selector %q", part)
+				}
+				segment.key, segment.value = key, value
+			} else {
+				index, err := strconv.Atoi(sel)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("list selector %q is neither an index nor a field=value pair", part)
+				}
+				segment.index = index
+			}
+		}
+		if segment.field == "" || strings.ContainsAny(segment.field, "[]") {
+			return nil, fmt.Errorf("invalid field %q", part)
+		}
+		fields = append(fields, segment)
+	}
+	return fields, nil
+}
+
+// find returns the index of the list element selected by the segment, -1 when
+// there is none.
+func (s pathSegment) find(list []interface{}) int {
+	if s.key == "" {
+		if s.index < len(list) {
+			return s.index
+		}
+		return -1
+	}
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := m[s.key]; ok && fmt.Sprint(value) == s.value {
+			return i
+		}
+	}
+	return -1
+}
+
+// preserveField copies the field at path from live into desired, removing it
+// from desired when it is not set on the live object. List elements missing
+// from desired are not added.
+func preserveField(desired, live map[string]interface{}, path fieldPath) {
+	value, found := lookupField(live, path)
+	if !found {
+		removeField(desired, path)
+		return
+	}
+	setField(desired, runtime.DeepCopyJSONValue(value), path)
+}
+
+func lookupField(obj map[string]interface{}, path fieldPath) (interface{}, bool) {
+	var current interface{} = obj
+	for _, segment := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[segment.field]; !ok {
+			return nil, false
+		}
+		if segment.selector {
+			list, _ := current.([]interface{})
+			i := segment.find(list)
+			if i < 0 {
+				return nil, false
+			}
+			current = list[i]
+		}
+	}
+	return current, true
+}
+
+func setField(obj map[string]interface{}, value interface{}, path fieldPath) {
+	current := obj
+	for n, segment := range path {
+		last := n == len(path)-1
+		if !segment.selector {
+			if last {
+				current[segment.field] = value
+				return
+			}
+			next, ok := current[segment.field].(map[string]interface{})
+			if !ok {
+				if _, exists := current[segment.field]; exists {
+					return
+				}
+				next = map[string]interface{}{}
+				current[segment.field] = next
+			}
+			current = next
+			continue
+		}
+
+		list, _ := current[segment.field].([]interface{})
+		i := segment.find(list)
+		if i < 0 {
+			return
+		}
+		if last {
+			list[i] = value
+			return
+		}
+		next, ok := list[i].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+}
+
+func removeField(obj map[string]interface{}, path fieldPath) {
+	current := obj
+	for n, segment := range path {
+		last := n == len(path)-1
+		if !segment.selector {
+			if last {
+				delete(current, segment.field)
+				return
+			}
+			next, ok := current[segment.field].(map[string]interface{})
+			if !ok {
+				return
+			}
+			current = next
+			continue
+		}
+
+		list, _ := current[segment.field].([]interface{})
+		i := segment.find(list)
+		if i < 0 {
+			return
+		}
+		if last {
+			current[segment.field] = append(list[:i:i], list[i+1:]...)
+			return
+		}
+		next, ok := list[i].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+}
+
+// driftedFields returns the paths of the values set in desired that differ in
+// live. Fields only present on the live object, like the ones defaulted by the
+// API server, are not considered drift.
+func driftedFields(desired, live interface{}, path string) []string {
+	switch d := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		l, _ := live.(map[string]interface{})
+		var drifted []string
+		for key, value := range d {
+			drifted = append(drifted, driftedFields(value, l[key], joinPath(path, key))...)
+		}
+		sort.Strings(drifted)
+		return drifted
+	case []interface{}:
+		l, _ := live.([]interface{})
+		if len(l) != len(d) {
+			return []string{path}
+		}
+		var drifted []string
+		for i := range d {
+			drifted = append(drifted, driftedFields(d[i], l[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return drifted
+	default:
+		if !equality.Semantic.DeepEqual(desired, live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// event records an event on the OperatorConfig when a recorder is configured.
+func (r *OperatorConfigReconciler) event(oc *cranev1alpha1.OperatorConfig, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(oc, eventType, reason, messageFmt, args...)
+}
+
+// unmanaged reports whether the object was marked with the unmanaged
+// annotation, listing it in status.unmanaged. Unmanaged objects are neither
+// updated nor pruned.
+func (r *OperatorConfigReconciler) unmanaged(oc *cranev1alpha1.OperatorConfig, obj client.Object) (bool, error) {
+	if obj.GetAnnotations()[UnmanagedAnnotation] != "true" {
+		return false, nil
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return false, err
+	}
+	oc.Status.Unmanaged = append(oc.Status.Unmanaged, cranev1alpha1.ResourceReference{
+		Kind:      gvk.Kind,
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	})
+	return true, nil
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Drift", func() {
+	var deploy *appsv1.Deployment
+	var deplKey types.NamespacedName
+	var r *OperatorConfigReconciler
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: OwnerConfigName,
+				UID:  "test",
+			},
+		}
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		deploy = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("deploy-%d", rand.Int31()), //nolint:gosec
+				Namespace: "default",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"foo": "bar"},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{"foo": "bar"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "busybox",
+								Image: "busybox",
+							},
+						},
+					},
+				},
+			},
+		}
+		deplKey = types.NamespacedName{Name: deploy.Name, Namespace: deploy.Namespace}
+	})
+
+	reconcile := func() {
+		tmp, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deploy)
+		Expect(err).NotTo(HaveOccurred())
+		un := unstructured.Unstructured{Object: tmp}
+		Expect(r.reconcileDeployment(un.DeepCopy(), context.TODO(), imageFn, log.FromContext(context.TODO()), oc)).To(Succeed())
+	}
+
+	addDebugEnv := func() {
+		live := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, live)).To(Succeed())
+		live.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "DEBUG", Value: "true"}}
+		Expect(c.Update(context.TODO(), live)).To(Succeed())
+	}
+
+	It("ignores fields defaulted by the API server", func() {
+		rendered, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deploy)
+		Expect(err).NotTo(HaveOccurred())
+
+		live := deploy.DeepCopy()
+		live.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+		liveContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(driftedFields(rendered, liveContent, "")).To(BeEmpty())
+	})
+
+	It("reports the paths of drifted fields", func() {
+		rendered, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deploy)
+		Expect(err).NotTo(HaveOccurred())
+
+		live := deploy.DeepCopy()
+		live.Spec.Template.Spec.Containers[0].Image = "nginx"
+		liveContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(driftedFields(rendered, liveContent, "")).To(ConsistOf("spec.template.spec.containers[0].image"))
+	})
+
+	It("corrects drift by default", func() {
+		reconcile()
+		addDebugEnv()
+		reconcile()
+
+		fetched := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+		Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+	})
+
+	It("only reports drift in Report mode", func() {
+		oc.Spec.Drift = &cranev1alpha1.DriftPolicy{Mode: cranev1alpha1.DriftModeReport}
+		reconcile()
+		addDebugEnv()
+		deploy.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "DEBUG", Value: "false"}}
+		reconcile()
+
+		fetched := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+		Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "DEBUG", Value: "true"}))
+		Expect(oc.Status.Drift).To(HaveLen(1))
+		Expect(oc.Status.Drift[0].Name).To(Equal(deploy.Name))
+		Expect(oc.Status.Drift[0].Fields).To(ConsistOf("spec.template.spec.containers[0].env[0].value"))
+	})
+
+	It("reports drift of unstructured resources", func() {
+		oc.Spec.Drift = &cranev1alpha1.DriftPolicy{Mode: cranev1alpha1.DriftModeReport}
+		name := fmt.Sprintf("cm-%d", rand.Int31()) //nolint:gosec
+		apply := func() {
+			cm := &unstructured.Unstructured{}
+			cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+			cm.SetName(name)
+			cm.SetNamespace("default")
+			_, err := r.createOrPatch(context.TODO(), oc, cm, func() error {
+				return unstructured.SetNestedField(cm.Object, "rendered", "data", "value")
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		apply()
+		live := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, live)).To(Succeed())
+		live.Data["value"] = "edited"
+		Expect(c.Update(context.TODO(), live)).To(Succeed())
+		apply()
+
+		fetched := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, fetched)).To(Succeed())
+		Expect(fetched.Data).To(HaveKeyWithValue("value", "edited"))
+		Expect(oc.Status.Drift).To(HaveLen(1))
+		Expect(oc.Status.Drift[0].Kind).To(Equal("ConfigMap"))
+		Expect(oc.Status.Drift[0].Fields).To(ConsistOf("data.value"))
+	})
+
+	It("does not overwrite ignored fields", func() {
+		oc.Spec.Drift = &cranev1alpha1.DriftPolicy{
+			Ignore: []cranev1alpha1.DriftIgnore{{Kind: "Deployment", Paths: []string{"spec.template.spec.containers"}}},
+		}
+		reconcile()
+		addDebugEnv()
+		reconcile()
+
+		fetched := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+		Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "DEBUG", Value: "true"}))
+	})
+
+	It("does not overwrite ignored list elements", func() {
+		for _, path := range []string{
+			"spec.template.spec.containers[0].env",
+			"spec.template.spec.containers[name=busybox].env",
+		} {
+			oc.Spec.Drift = &cranev1alpha1.DriftPolicy{
+				Ignore: []cranev1alpha1.DriftIgnore{{Kind: "Deployment", Paths: []string{path}}},
+			}
+			reconcile()
+			live := &appsv1.Deployment{}
+			Expect(c.Get(context.TODO(), deplKey, live)).To(Succeed())
+			live.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "DEBUG", Value: "true"}}
+			live.Spec.Template.Spec.Containers[0].Image = "nginx"
+			Expect(c.Update(context.TODO(), live)).To(Succeed())
+			reconcile()
+
+			fetched := &appsv1.Deployment{}
+			Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+			Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "DEBUG", Value: "true"}), path)
+			Expect(fetched.Spec.Template.Spec.Containers[0].Image).To(Equal("busybox"), path)
+		}
+	})
+
+	It("reports invalid ignore paths", func() {
+		oc.Spec.Drift = &cranev1alpha1.DriftPolicy{
+			Ignore: []cranev1alpha1.DriftIgnore{{
+				Kind: "Deployment",
+				Paths: []string{
+					"spec.replicas",
+					"spec.template.spec.containers[name=proxy",
+					"spec.template.spec.containers[first].env",
+					"spec..replicas",
+				},
+			}},
+		}
+		r.checkDriftIgnores(oc)
+		Expect(oc.Status.InvalidDriftIgnores).To(HaveLen(3))
+		Expect(oc.Status.InvalidDriftIgnores[0].Path).To(Equal("spec.template.spec.containers[name=proxy"))
+
+		// Invalid paths are skipped, the resources are still applied
+		reconcile()
+		addDebugEnv()
+		reconcile()
+
+		fetched := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+		Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+	})
+
+	It("skips resources annotated as unmanaged", func() {
+		reconcile()
+
+		live := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, live)).To(Succeed())
+		live.Annotations = map[string]string{UnmanagedAnnotation: "true"}
+		live.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "DEBUG", Value: "true"}}
+		Expect(c.Update(context.TODO(), live)).To(Succeed())
+		reconcile()
+
+		fetched := &appsv1.Deployment{}
+		Expect(c.Get(context.TODO(), deplKey, fetched)).To(Succeed())
+		Expect(fetched.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "DEBUG", Value: "true"}))
+		Expect(oc.Status.Unmanaged).To(ConsistOf(cranev1alpha1.ResourceReference{
+			Kind:      "Deployment",
+			Name:      deploy.Name,
+			Namespace: deploy.Namespace,
+		}))
+	})
+})
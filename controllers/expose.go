@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ExposedLabel marks the Routes and Ingresses created for spec.expose
+	ExposedLabel = "crane.konveyor.io/exposed"
+
+	// TLSSecretAnnotation names the TLS Secret whose certificate is inlined
+	// into a Route, Routes can not reference Secrets
+	TLSSecretAnnotation = "crane.konveyor.io/tls-secret"
+)
+
+func (r *OperatorConfigReconciler) reconcileRoute(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj routev1.Route
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	err = r.routeCertificates(ctx, oc, &obj)
+	if err != nil {
+		return err
+	}
+
+	route := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, route, func() error {
+		err = controllerutil.SetControllerReference(oc, route, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		// Keep the host generated by the router
+		host := route.Spec.Host
+		route.Spec = obj.Spec
+		if route.Spec.Host == "" {
+			route.Spec.Host = host
+		}
+		if len(obj.Labels) > 0 {
+			route.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			route.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("Route successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+// routeCertificates inlines the certificates of a reencrypt Route: the one
+// served for the host from the TLS Secret annotation and the CA of the
+// operand Service, unless it is issued by the service CA the router trusts.
+func (r *OperatorConfigReconciler) routeCertificates(ctx context.Context, oc *cranev1alpha1.OperatorConfig, route *routev1.Route) error {
+	if route.Spec.TLS == nil {
+		return nil
+	}
+
+	if name := route.Annotations[TLSSecretAnnotation]; name != "" {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: route.Namespace}, secret)
+		if err != nil {
+			return err
+		}
+		route.Spec.TLS.Certificate = string(secret.Data[corev1.TLSCertKey])
+		route.Spec.TLS.Key = string(secret.Data[corev1.TLSPrivateKeyKey])
+	}
+
+	if certificateProviderFor(oc, oc.Status.Platform) == cranev1alpha1.CertificateProviderServiceCA {
+		return nil
+	}
+	for _, s := range servingCerts {
+		if s.service != route.Spec.To.Name {
+			continue
+		}
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: s.secret, Namespace: route.Namespace}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		route.Spec.TLS.DestinationCACertificate = string(secret.Data["ca.crt"])
+	}
+	return nil
+}
+
+func (r *OperatorConfigReconciler) reconcileIngress(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj networkingv1.Ingress
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, ingress, func() error {
+		err = controllerutil.SetControllerReference(oc, ingress, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		ingress.Spec = obj.Spec
+		if len(obj.Labels) > 0 {
+			ingress.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			ingress.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("Ingress successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+// pruneExposed deletes the Routes and Ingresses of the operands no longer
+// listed in spec.expose.
+func (r *OperatorConfigReconciler) pruneExposed(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	exposed := map[string]bool{}
+	for _, expose := range oc.Spec.Expose {
+		exposed[expose.Component] = true
+	}
+
+	lists := []client.ObjectList{&networkingv1.IngressList{}}
+	if served(r.restMapper(), routeGVK) {
+		lists = append(lists, &routev1.RouteList{})
+	}
+	for _, list := range lists {
+		err := r.List(ctx, list, client.InNamespace(InstallNamespace), client.MatchingLabels{ExposedLabel: "true"})
+		if err != nil {
+			return err
+		}
+		objs, err := apimeta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			obj := o.(client.Object)
+			if exposed[obj.GetName()] || !metav1.IsControlledBy(obj, oc) {
+				continue
+			}
+			unmanaged, err := r.unmanaged(oc, obj)
+			if err != nil {
+				return err
+			}
+			if unmanaged {
+				continue
+			}
+			err = r.Delete(ctx, obj)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			log.Info("Removed exposure of operand", "name", obj.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Expose", func() {
+	var data manifestData
+
+	BeforeEach(func() {
+		data = manifestData{
+			Spec: cranev1alpha1.OperatorConfigSpec{
+				Expose: []cranev1alpha1.ExposeConfig{{
+					Component:     "proxy",
+					Host:          "crane-proxy.apps.example.com",
+					TLSSecretName: "crane-proxy-tls",
+					Annotations:   map[string]string{"haproxy.router.openshift.io/timeout": "5m"},
+				}},
+			},
+			Namespace: "crane-test",
+		}
+	})
+
+	render := func(obj interface{}) {
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-expose.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[0].Object, obj)).To(Succeed())
+	}
+
+	It("renders a reencrypt Route on OpenShift", func() {
+		data.Platform = cranev1alpha1.PlatformOpenShift
+
+		route := &routev1.Route{}
+		render(route)
+		Expect(route.Spec.Host).To(Equal("crane-proxy.apps.example.com"))
+		Expect(route.Spec.To.Name).To(Equal("proxy"))
+		Expect(route.Spec.TLS.Termination).To(Equal(routev1.TLSTerminationReencrypt))
+		Expect(route.Annotations).To(HaveKeyWithValue(TLSSecretAnnotation, "crane-proxy-tls"))
+		Expect(route.Annotations).To(HaveKeyWithValue("haproxy.router.openshift.io/timeout", "5m"))
+	})
+
+	It("renders an Ingress on Kubernetes", func() {
+		data.Platform = cranev1alpha1.PlatformKubernetes
+
+		ingress := &networkingv1.Ingress{}
+		render(ingress)
+		Expect(ingress.Spec.TLS).To(ConsistOf(networkingv1.IngressTLS{
+			Hosts:      []string{"crane-proxy.apps.example.com"},
+			SecretName: "crane-proxy-tls",
+		}))
+		Expect(ingress.Spec.Rules[0].Host).To(Equal("crane-proxy.apps.example.com"))
+		Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name).To(Equal("proxy"))
+	})
+
+	It("removes the Ingresses of operands no longer exposed", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc := &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+		oc.Spec.Expose = []cranev1alpha1.ExposeConfig{{Component: "proxy"}}
+
+		for _, name := range []string{"proxy", "secret-service"} {
+			ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: InstallNamespace,
+				Labels:    map[string]string{ExposedLabel: "true"},
+			}}
+			Expect(controllerutil.SetControllerReference(oc, ingress, scheme.Scheme)).To(Succeed())
+			Expect(c.Create(context.TODO(), ingress)).To(Succeed())
+		}
+
+		Expect(r.pruneExposed(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		list := &networkingv1.IngressList{}
+		Expect(c.List(context.TODO(), list, client.InNamespace(InstallNamespace))).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("proxy"))
+		Expect(c.Delete(context.TODO(), &list.Items[0])).To(Succeed())
+	})
+
+	It("keeps the unmanaged Ingresses of operands no longer exposed", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc := &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+
+		ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name:        "proxy",
+			Namespace:   InstallNamespace,
+			Labels:      map[string]string{ExposedLabel: "true"},
+			Annotations: map[string]string{UnmanagedAnnotation: "true"},
+		}}
+		Expect(controllerutil.SetControllerReference(oc, ingress, scheme.Scheme)).To(Succeed())
+		Expect(c.Create(context.TODO(), ingress)).To(Succeed())
+
+		Expect(r.pruneExposed(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(ingress), ingress)).To(Succeed())
+		Expect(oc.Status.Unmanaged).To(ConsistOf(cranev1alpha1.ResourceReference{Kind: "Ingress", Name: "proxy", Namespace: InstallNamespace}))
+		Expect(c.Delete(context.TODO(), ingress)).To(Succeed())
+	})
+})
@@ -17,11 +17,41 @@ limitations under the License.
 package controllers
 
 import (
+	"fmt"
 	"os"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// ImageKeyAnnotationPrefix followed by the name of a container (or step)
+// selects the image key the container is run with, e.g.
+// image.crane.konveyor.io/skopeo-sync: skopeo
+const ImageKeyAnnotationPrefix = "image.crane.konveyor.io/"
+
 type ImageFunction func() string
 
+// images maps the image keys available to the operand manifests to the
+// function resolving them.
+var images = map[string]ImageFunction{
+	"crane-runner":         CraneRunnerImage,
+	"crane-ui-plugin":      CraneUIPluginImage,
+	"crane-reverse-proxy":  CraneReverseProxyImage,
+	"crane-secret-service": CraneSecretServiceImage,
+	"skopeo":               SkopeoImage,
+}
+
+// resolvedImages returns the image of each image key.
+func resolvedImages() map[string]string {
+	resolved := map[string]string{}
+	for key, imageFn := range images {
+		resolved[key] = imageFn()
+	}
+	return resolved
+}
+
 func CraneRunnerImage() string {
 	return getEnvVar("RELATED_IMAGE_CRANE_RUNNER", "quay.io/konveyor/crane-runner:latest")
 }
@@ -38,6 +68,85 @@ func CraneSecretServiceImage() string {
 	return getEnvVar("RELATED_IMAGE_CRANE_SECRET_SERVICE", "quay.io/konveyor/crane-secret-service:latest")
 }
 
+// SkopeoImage runs the skopeo steps of the ClusterTasks, the crane-runner
+// image ships skopeo and is used unless overridden.
+func SkopeoImage() string {
+	return getEnvVar("RELATED_IMAGE_SKOPEO", CraneRunnerImage())
+}
+
+// imageFor returns the image of the named container or step of a resource.
+// Containers without an image key annotation on the resource run the image of
+// the operand.
+func imageFor(annotations map[string]string, name string, imageFn ImageFunction) (string, error) {
+	key, ok := annotations[ImageKeyAnnotationPrefix+name]
+	if !ok {
+		return imageFn(), nil
+	}
+	return imageForKey(key)
+}
+
+// imageForKey resolves an image key. Keys without a default image, like the
+// ones of additional sidecars, are read from the RELATED_IMAGE_<KEY>
+// environment variable.
+func imageForKey(key string) (string, error) {
+	if imageFn, ok := images[key]; ok {
+		return imageFn(), nil
+	}
+
+	env := "RELATED_IMAGE_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	if value, ok := os.LookupEnv(env); ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("no image configured for image key %s, set the %s environment variable", key, env)
+}
+
+// applyPodPullSettings adds the image pull secrets and sets the image pull
+// policy of the OperatorConfig on the pod spec of an operand.
+func applyPodPullSettings(podSpec *corev1.PodSpec, oc *cranev1alpha1.OperatorConfig) {
+	for _, secret := range oc.Spec.ImagePullSecrets {
+		if !containsPullSecret(podSpec.ImagePullSecrets, secret) {
+			podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, secret)
+		}
+	}
+
+	if oc.Spec.ImagePullPolicy == "" {
+		return
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].ImagePullPolicy = oc.Spec.ImagePullPolicy
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].ImagePullPolicy = oc.Spec.ImagePullPolicy
+	}
+}
+
+// applyTaskPullSettings sets the image pull policy of the OperatorConfig on
+// the step template of a ClusterTask. Pull secrets of task pods come from the
+// ServiceAccount of the TaskRun and can not be set on the task.
+func applyTaskPullSettings(taskSpec *pipelinev1beta1.TaskSpec, oc *cranev1alpha1.OperatorConfig) {
+	if oc.Spec.ImagePullPolicy == "" {
+		return
+	}
+	if taskSpec.StepTemplate == nil {
+		taskSpec.StepTemplate = &corev1.Container{}
+	}
+	taskSpec.StepTemplate.ImagePullPolicy = oc.Spec.ImagePullPolicy
+	// Steps setting their own pull policy would take precedence over the
+	// template.
+	for i := range taskSpec.Steps {
+		taskSpec.Steps[i].ImagePullPolicy = ""
+	}
+}
+
+func containsPullSecret(secrets []corev1.LocalObjectReference, secret corev1.LocalObjectReference) bool {
+	for _, s := range secrets {
+		if s.Name == secret.Name {
+			return true
+		}
+	}
+	return false
+}
+
 func getEnvVar(key, def string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"os"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Images", func() {
+	AfterEach(func() {
+		os.Unsetenv("RELATED_IMAGE_SKOPEO")
+		os.Unsetenv("RELATED_IMAGE_METRICS_EXPORTER")
+	})
+
+	It("uses the image of the operand by default", func() {
+		image, err := imageFor(nil, "proxy", imageFn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal("busybox"))
+	})
+
+	It("resolves the image key selected by annotation", func() {
+		os.Setenv("RELATED_IMAGE_SKOPEO", "quay.io/skopeo/stable:v1.9")
+		annotations := map[string]string{ImageKeyAnnotationPrefix + "skopeo-sync": "skopeo"}
+
+		image, err := imageFor(annotations, "skopeo-sync", imageFn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal("quay.io/skopeo/stable:v1.9"))
+
+		image, err = imageFor(annotations, "crane-skopeo-sync-gen", imageFn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal("busybox"))
+	})
+
+	It("reads keys without a default image from the environment", func() {
+		_, err := imageForKey("metrics-exporter")
+		Expect(err).To(HaveOccurred())
+
+		os.Setenv("RELATED_IMAGE_METRICS_EXPORTER", "quay.io/example/exporter:v1")
+		image, err := imageForKey("metrics-exporter")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal("quay.io/example/exporter:v1"))
+	})
+
+	It("applies the pull settings to pods", func() {
+		oc := &cranev1alpha1.OperatorConfig{Spec: cranev1alpha1.OperatorConfigSpec{
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror-pull-secret"}},
+			ImagePullPolicy:  corev1.PullIfNotPresent,
+		}}
+		podSpec := &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "proxy", ImagePullPolicy: corev1.PullAlways}},
+		}
+
+		applyPodPullSettings(podSpec, oc)
+		applyPodPullSettings(podSpec, oc)
+		Expect(podSpec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "mirror-pull-secret"}))
+		Expect(podSpec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+	})
+
+	It("sets the pull policy on the step template of tasks", func() {
+		oc := &cranev1alpha1.OperatorConfig{Spec: cranev1alpha1.OperatorConfigSpec{ImagePullPolicy: corev1.PullIfNotPresent}}
+		taskSpec := &pipelinev1beta1.TaskSpec{
+			Steps: []pipelinev1beta1.Step{{Container: corev1.Container{Name: "crane-export", ImagePullPolicy: corev1.PullAlways}}},
+		}
+
+		applyTaskPullSettings(taskSpec, oc)
+		Expect(taskSpec.StepTemplate.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		Expect(taskSpec.Steps[0].ImagePullPolicy).To(BeEmpty())
+	})
+})
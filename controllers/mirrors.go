@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+)
+
+// mirrorImage rewrites the image with the first of the mirrors matching it. A
+// source matches whole path components only, quay.io/konveyor matches
+// quay.io/konveyor/crane-runner:latest but not quay.io/konveyor-dev/crane.
+func mirrorImage(image string, mirrors []cranev1alpha1.ImageMirror) string {
+	for _, mirror := range mirrors {
+		if !strings.HasPrefix(image, mirror.Source) {
+			continue
+		}
+		rest := image[len(mirror.Source):]
+		if rest == "" || strings.ContainsAny(rest[:1], "/:@") {
+			return mirror.Mirror + rest
+		}
+	}
+	return image
+}
+
+// rewriteImage applies the image mirrors of the OperatorConfig to the image
+// and records the rewrite in the status.
+func rewriteImage(oc *cranev1alpha1.OperatorConfig, image string) string {
+	mirrored := mirrorImage(image, oc.Spec.ImageMirrors)
+	if mirrored != image {
+		recordRewrite(&oc.Status, cranev1alpha1.ImageRewrite{Source: image, Mirror: mirrored})
+	}
+	return mirrored
+}
+
+// scriptReferences caches the expression matching the image references of
+// each mirror source in scripts.
+var scriptReferences sync.Map
+
+// scriptReference returns the expression matching the image references of
+// the mirror source in scripts.
+func scriptReference(source string) *regexp.Regexp {
+	if reference, ok := scriptReferences.Load(source); ok {
+		return reference.(*regexp.Regexp)
+	}
+	reference := regexp.MustCompile(regexp.QuoteMeta(source) + `[/:@][^\s"'` + "`" + `)]*`)
+	scriptReferences.Store(source, reference)
+	return reference
+}
+
+// rewriteScriptImages applies the image mirrors of the OperatorConfig to the
+// image references found in a script.
+func rewriteScriptImages(oc *cranev1alpha1.OperatorConfig, script string) string {
+	for _, mirror := range oc.Spec.ImageMirrors {
+		script = scriptReference(mirror.Source).ReplaceAllStringFunc(script, func(image string) string {
+			return rewriteImage(oc, image)
+		})
+	}
+	return script
+}
+
+// recordRewrite adds the rewrite to the status, keeping the list sorted so the
+// status does not change between reconciles.
+func recordRewrite(status *cranev1alpha1.OperatorConfigStatus, rewrite cranev1alpha1.ImageRewrite) {
+	i := sort.Search(len(status.RewrittenImages), func(i int) bool {
+		return status.RewrittenImages[i].Source >= rewrite.Source
+	})
+	if i < len(status.RewrittenImages) && status.RewrittenImages[i] == rewrite {
+		return
+	}
+	status.RewrittenImages = append(status.RewrittenImages, cranev1alpha1.ImageRewrite{})
+	copy(status.RewrittenImages[i+1:], status.RewrittenImages[i:])
+	status.RewrittenImages[i] = rewrite
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Image mirrors", func() {
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{
+			Spec: cranev1alpha1.OperatorConfigSpec{
+				ImageMirrors: []cranev1alpha1.ImageMirror{
+					{Source: "quay.io/konveyor", Mirror: "registry.example.com/konveyor"},
+				},
+			},
+		}
+	})
+
+	It("rewrites matching images and records the rewrite", func() {
+		Expect(rewriteImage(oc, "quay.io/konveyor/crane-runner:latest")).To(Equal("registry.example.com/konveyor/crane-runner:latest"))
+		Expect(rewriteImage(oc, "quay.io/konveyor/crane-runner:latest")).To(Equal("registry.example.com/konveyor/crane-runner:latest"))
+		Expect(oc.Status.RewrittenImages).To(ConsistOf(cranev1alpha1.ImageRewrite{
+			Source: "quay.io/konveyor/crane-runner:latest",
+			Mirror: "registry.example.com/konveyor/crane-runner:latest",
+		}))
+	})
+
+	It("only matches whole path components", func() {
+		Expect(rewriteImage(oc, "quay.io/konveyor-dev/crane-runner:latest")).To(Equal("quay.io/konveyor-dev/crane-runner:latest"))
+		Expect(oc.Status.RewrittenImages).To(BeEmpty())
+	})
+
+	It("rewrites images referenced in scripts", func() {
+		script := `crane transfer-pvc --rsync-image="quay.io/konveyor/rsync-transfer:latest" --verify`
+
+		Expect(rewriteScriptImages(oc, script)).To(Equal(`crane transfer-pvc --rsync-image="registry.example.com/konveyor/rsync-transfer:latest" --verify`))
+	})
+
+	It("keeps the rewrites sorted", func() {
+		rewriteImage(oc, "quay.io/konveyor/crane-secret-service:latest")
+		rewriteImage(oc, "quay.io/konveyor/crane-reverse-proxy:latest")
+
+		Expect(oc.Status.RewrittenImages).To(HaveLen(2))
+		Expect(oc.Status.RewrittenImages[0].Source).To(Equal("quay.io/konveyor/crane-reverse-proxy:latest"))
+	})
+
+	It("only records the rewrites of deployed images", func() {
+		r := &OperatorConfigReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(meta.NewDefaultRESTMapper(nil)).Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		data, err := r.manifestData(context.TODO(), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Images).To(HaveKeyWithValue("crane-ui-plugin", "registry.example.com/konveyor/crane-ui-plugin:latest"))
+		Expect(oc.Status.RewrittenImages).To(BeEmpty())
+	})
+})
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ClusterMonitoringLabel lets the OpenShift cluster monitoring stack
+	// scrape the install namespace
+	ClusterMonitoringLabel = "openshift.io/cluster-monitoring"
+
+	// namespaceRecheck is how often a terminating install namespace is
+	// checked, it is recreated once it is gone
+	namespaceRecheck = 10 * time.Second
+)
+
+// podSecurityLabels enforce the restricted Pod Security Standard the operand
+// pods are written for.
+var podSecurityLabels = map[string]string{
+	"pod-security.kubernetes.io/enforce": "restricted",
+	"pod-security.kubernetes.io/audit":   "restricted",
+	"pod-security.kubernetes.io/warn":    "restricted",
+}
+
+// namespaceTerminatingError is returned while the install namespace is being
+// deleted, nothing can be created in it until it is gone.
+type namespaceTerminatingError struct {
+	namespace string
+}
+
+func (e namespaceTerminatingError) Error() string {
+	return fmt.Sprintf("Namespace %s is terminating, the operands are installed once it is deleted", e.namespace)
+}
+
+// reconcileNamespace creates the install namespace, unless OLM already did,
+// and labels it. The namespace is not owned by the OperatorConfig, deleting
+// the OperatorConfig must not delete the operator along with its namespace.
+func (r *OperatorConfigReconciler) reconcileNamespace(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	namespace := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: InstallNamespace}, namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && namespace.Status.Phase == corev1.NamespaceTerminating {
+		return namespaceTerminatingError{namespace: InstallNamespace}
+	}
+
+	namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: InstallNamespace}}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, namespace, func() error {
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+		for key, value := range namespaceLabels(oc.Status.Platform) {
+			namespace.Labels[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Namespace successfully reconciled", "operation", op)
+	return nil
+}
+
+// namespaceLabels returns the labels set on the install namespace.
+func namespaceLabels(platform cranev1alpha1.Platform) map[string]string {
+	labels := map[string]string{}
+	for key, value := range podSecurityLabels {
+		labels[key] = value
+	}
+	if platform == cranev1alpha1.PlatformOpenShift {
+		labels[ClusterMonitoringLabel] = "true"
+	}
+	return labels
+}
+
+// installNamespace matches the install namespace.
+func installNamespace(obj client.Object) bool {
+	return obj.GetName() == InstallNamespace
+}
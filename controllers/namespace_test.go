@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Namespace", func() {
+	var (
+		r  *OperatorConfigReconciler
+		oc *cranev1alpha1.OperatorConfig
+	)
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc = &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName}}
+		oc.Status.Platform = cranev1alpha1.PlatformOpenShift
+	})
+
+	AfterEach(func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: InstallNamespace}}
+		Expect(client.IgnoreNotFound(c.Delete(context.TODO(), namespace))).To(Succeed())
+	})
+
+	It("creates the labeled install namespace", func() {
+		Expect(r.reconcileNamespace(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		namespace := &corev1.Namespace{}
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: InstallNamespace}, namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+		Expect(namespace.Labels).To(HaveKeyWithValue(ClusterMonitoringLabel, "true"))
+		Expect(namespace.OwnerReferences).To(BeEmpty())
+	})
+
+	It("keeps the labels of an existing namespace", func() {
+		oc.Status.Platform = cranev1alpha1.PlatformKubernetes
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   InstallNamespace,
+			Labels: map[string]string{"olm.operatorgroup.uid/1234": ""},
+		}}
+		Expect(c.Create(context.TODO(), namespace)).To(Succeed())
+
+		Expect(r.reconcileNamespace(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(namespace), namespace)).To(Succeed())
+		Expect(namespace.Labels).To(HaveKey("olm.operatorgroup.uid/1234"))
+		Expect(namespace.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/warn", "restricted"))
+		Expect(namespace.Labels).NotTo(HaveKey(ClusterMonitoringLabel))
+	})
+
+	It("reports a terminating namespace", func() {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: InstallNamespace},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+		}
+		Expect(c.Create(context.TODO(), namespace)).To(Succeed())
+
+		err := r.reconcileNamespace(context.TODO(), log.FromContext(context.TODO()), oc)
+		Expect(err).To(Equal(namespaceTerminatingError{namespace: InstallNamespace}))
+	})
+})
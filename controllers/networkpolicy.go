@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func (r *OperatorConfigReconciler) reconcileNetworkPolicy(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj networkingv1.NetworkPolicy
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	networkPolicy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, networkPolicy, func() error {
+		err = controllerutil.SetControllerReference(oc, networkPolicy, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		networkPolicy.Spec = obj.Spec
+		if len(obj.Labels) > 0 {
+			networkPolicy.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			networkPolicy.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("NetworkPolicy successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+// pruneNetworkPolicies deletes the NetworkPolicies created by the operator
+// once they are disabled by spec.networkPolicy.
+func (r *OperatorConfigReconciler) pruneNetworkPolicies(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	if oc.Spec.NetworkPolicy == nil || !oc.Spec.NetworkPolicy.Disabled {
+		return nil
+	}
+
+	list := &networkingv1.NetworkPolicyList{}
+	err := r.List(ctx, list, client.InNamespace(InstallNamespace))
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		networkPolicy := &list.Items[i]
+		if !metav1.IsControlledBy(networkPolicy, oc) {
+			continue
+		}
+		unmanaged, err := r.unmanaged(oc, networkPolicy)
+		if err != nil {
+			return err
+		}
+		if unmanaged {
+			continue
+		}
+		err = r.Delete(ctx, networkPolicy)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Removed disabled NetworkPolicy", "name", networkPolicy.Name)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("NetworkPolicies", func() {
+	var data manifestData
+
+	BeforeEach(func() {
+		data = manifestData{Namespace: "crane-test", Platform: cranev1alpha1.PlatformOpenShift}
+	})
+
+	render := func() []*unstructured.Unstructured {
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-network-policies.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		return objs
+	}
+
+	renderPolicy := func() *networkingv1.NetworkPolicy {
+		objs := render()
+		Expect(objs).To(HaveLen(1))
+		networkPolicy := &networkingv1.NetworkPolicy{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[0].Object, networkPolicy)).To(Succeed())
+		return networkPolicy
+	}
+
+	It("allows ingress from the console and pipeline pods only", func() {
+		networkPolicy := renderPolicy()
+		Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+		Expect(networkPolicy.Spec.Ingress).To(HaveLen(1))
+
+		from := networkPolicy.Spec.Ingress[0].From
+		Expect(from).To(HaveLen(2))
+		Expect(from[0].PodSelector.MatchLabels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "tekton-pipelines"))
+		Expect(from[1].NamespaceSelector.MatchLabels).To(HaveKeyWithValue("kubernetes.io/metadata.name", "openshift-console"))
+	})
+
+	It("allows ingress from the routers and listed namespaces", func() {
+		data.Spec.Expose = []cranev1alpha1.ExposeConfig{{Component: "proxy"}}
+		data.Spec.NetworkPolicy = &cranev1alpha1.NetworkPolicyConfig{IngressNamespaces: []string{"ingress-nginx"}}
+
+		from := renderPolicy().Spec.Ingress[0].From
+		Expect(from).To(HaveLen(4))
+		Expect(from[2].NamespaceSelector.MatchLabels).To(HaveKeyWithValue("network.openshift.io/policy-group", "ingress"))
+		Expect(from[3].NamespaceSelector.MatchLabels).To(HaveKeyWithValue("kubernetes.io/metadata.name", "ingress-nginx"))
+	})
+
+	It("restricts egress to the listed CIDRs and DNS", func() {
+		data.Spec.NetworkPolicy = &cranev1alpha1.NetworkPolicyConfig{EgressCIDRs: []string{"10.0.0.0/16", "192.168.1.10/32"}}
+
+		networkPolicy := renderPolicy()
+		Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+		Expect(networkPolicy.Spec.Egress).To(HaveLen(2))
+		Expect(networkPolicy.Spec.Egress[0].To).To(BeEmpty())
+		Expect(networkPolicy.Spec.Egress[1].To).To(ConsistOf(
+			networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16"}},
+			networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.1.10/32"}},
+		))
+	})
+
+	It("renders nothing when disabled", func() {
+		data.Spec.NetworkPolicy = &cranev1alpha1.NetworkPolicyConfig{Disabled: true}
+		Expect(render()).To(BeEmpty())
+	})
+
+	It("removes the NetworkPolicies once disabled", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc := &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+
+		networkPolicy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "crane-operands", Namespace: InstallNamespace}}
+		Expect(controllerutil.SetControllerReference(oc, networkPolicy, scheme.Scheme)).To(Succeed())
+		Expect(c.Create(context.TODO(), networkPolicy)).To(Succeed())
+
+		Expect(r.pruneNetworkPolicies(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(networkPolicy), networkPolicy)).To(Succeed())
+
+		oc.Spec.NetworkPolicy = &cranev1alpha1.NetworkPolicyConfig{Disabled: true}
+		Expect(r.pruneNetworkPolicies(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+		list := &networkingv1.NetworkPolicyList{}
+		Expect(c.List(context.TODO(), list, client.InNamespace(InstallNamespace))).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("keeps the unmanaged NetworkPolicies once disabled", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc := &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+		oc.Spec.NetworkPolicy = &cranev1alpha1.NetworkPolicyConfig{Disabled: true}
+
+		networkPolicy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{
+			Name:        "crane-operands",
+			Namespace:   InstallNamespace,
+			Annotations: map[string]string{UnmanagedAnnotation: "true"},
+		}}
+		Expect(controllerutil.SetControllerReference(oc, networkPolicy, scheme.Scheme)).To(Succeed())
+		Expect(c.Create(context.TODO(), networkPolicy)).To(Succeed())
+
+		Expect(r.pruneNetworkPolicies(context.TODO(), log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(networkPolicy), networkPolicy)).To(Succeed())
+		Expect(oc.Status.Unmanaged).To(ConsistOf(cranev1alpha1.ResourceReference{Kind: "NetworkPolicy", Name: "crane-operands", Namespace: InstallNamespace}))
+		Expect(c.Delete(context.TODO(), networkPolicy)).To(Succeed())
+	})
+})
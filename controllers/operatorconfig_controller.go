@@ -19,7 +19,6 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/go-logr/logr"
 	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
@@ -27,27 +26,42 @@ import (
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	Finalizer       = "openshift.konveyor.crane"
 	OwnerConfigName = "openshift-migration-toolkit"
+
+	// UnmanagedAnnotation set to "true" on a managed resource stops the
+	// operator from updating or pruning it
+	UnmanagedAnnotation = "crane.konveyor.io/unmanaged"
 )
 
 // Condition types
 const (
 	ReconcileCompleted = "ReconcileCompleted"
+	Paused             = "Paused"
+	Degraded           = "Degraded"
+	Available          = "Available"
+	DependencyMissing  = "DependencyMissing"
 )
 
 // Reasons
@@ -55,6 +69,15 @@ const (
 	InvalidName            = "InvalidName"
 	ReconcileComplete      = "ReconcileComplete"
 	ErrorCreatingResources = "ErrorCreatingResources"
+	PausedBySpec           = "PausedBySpec"
+	NotPaused              = "NotPaused"
+	UnpinnedImages         = "UnpinnedImages"
+	AsExpected             = "AsExpected"
+	ComponentsAvailable    = "ComponentsAvailable"
+	CertificatesNotReady   = "CertificatesNotReady"
+	NamespaceTerminating   = "NamespaceTerminating"
+	AllAPIsServed          = "AllAPIsServed"
+	APINotServed           = "APINotServed"
 )
 
 // An operand, we are defining as:
@@ -67,6 +90,9 @@ type operand struct {
 
 // operands is the set of components being managed by this operator
 var operands = []operand{
+	{
+		path: "trusted-ca-bundle.yaml",
+	},
 	{
 		path:    "crane-reverse-proxy.yaml",
 		imageFn: CraneReverseProxyImage,
@@ -75,6 +101,12 @@ var operands = []operand{
 		path:    "crane-secret-service.yaml",
 		imageFn: CraneSecretServiceImage,
 	},
+	{
+		path: "crane-expose.yaml",
+	},
+	{
+		path: "crane-network-policies.yaml",
+	},
 	{
 		path:    "crane-ui-plugin.yaml",
 		imageFn: CraneUIPluginImage,
@@ -88,18 +120,34 @@ var operands = []operand{
 // OperatorConfigReconciler reconciles a OperatorConfig object
 type OperatorConfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	mapper  *missingAPIMapper
+	watches *dynamicWatches
 }
 
 // rbac.authorization.k8s.io permissions are needed to create namespace limited role and rolebinding to create deployment and service within openshift-migration-toolkit
 //+kubebuilder:rbac:groups=crane.konveyor.io,resources=operatorconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crane.konveyor.io,resources=operatorconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crane.konveyor.io,resources=operatorconfigs/finalizers,verbs=update
-//+kubebuilder:rbac:groups=tekton.dev,resources=clustertasks,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=tekton.dev,resources=clustertasks;tasks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="apps",namespace=openshift-migration-toolkit,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=console.openshift.io,resources=consoleplugins,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",namespace=openshift-migration-toolkit,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",namespace=openshift-migration-toolkit,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",namespace=openshift-migration-toolkit,resources=configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=openshift-migration-toolkit,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,namespace=openshift-migration-toolkit,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies;apiservers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=consoles,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=route.openshift.io,namespace=openshift-migration-toolkit,resources=routes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=route.openshift.io,namespace=openshift-migration-toolkit,resources=routes/custom-host,verbs=create;update;patch
+//+kubebuilder:rbac:groups=networking.k8s.io,namespace=openshift-migration-toolkit,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,namespace=openshift-migration-toolkit,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -128,22 +176,9 @@ func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	if operatorConfig.Name != OwnerConfigName {
-		reason := fmt.Sprintf("Invalid name (%s)", operatorConfig.Name)
-		msg := fmt.Sprintf("Only one OperatorConfig supported per cluster and must be named '%s'", OwnerConfigName)
-		log.Info(fmt.Sprintf("%s: %s", reason, msg))
-		meta.SetStatusCondition(&operatorConfig.Status.Conditions, metav1.Condition{
-			Type:               ReconcileCompleted,
-			Status:             metav1.ConditionFalse,
-			Reason:             InvalidName,
-			Message:            fmt.Sprintf("%s: %s", reason, msg),
-			LastTransitionTime: metav1.Time{Time: time.Now()},
-		})
-		err := r.Status().Update(ctx, operatorConfig)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-
-		return ctrl.Result{}, nil
+		err := invalidNameError{name: operatorConfig.Name}
+		log.Info(err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, operatorConfig, err)
 	}
 
 	// Add Finalizer for this CR
@@ -158,7 +193,7 @@ func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	if operatorConfig.DeletionTimestamp != nil {
 		// clean up
-		err := r.cleanUpResources(ctx)
+		err := r.cleanUpResources(ctx, operatorConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -172,18 +207,67 @@ func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, nil
 	}
 
-	for _, o := range operands {
+	if operatorConfig.Spec.Paused {
+		log.Info("Reconciliation is paused")
+		return ctrl.Result{}, r.updateStatus(ctx, operatorConfig, nil)
+	}
+
+	// Watch the optional APIs installed since the last pass
+	if err := r.watches.sync(log); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	resetObservations(&operatorConfig.Status)
+	r.checkDriftIgnores(operatorConfig)
+	rendered, err := r.renderOperands(ctx, operatorConfig)
+	if err != nil {
+		log.Error(err, "Error rendering resources")
+		err := r.updateStatus(ctx, operatorConfig, err)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// Nothing is applied unless all the images pass the digest check
+	if err := checkImageDigests(rendered, operatorConfig); err != nil {
+		log.Info(err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, operatorConfig, err)
+	}
+
+	// The install namespace is recreated once a deletion finished
+	err = r.reconcileNamespace(ctx, log, operatorConfig)
+	if _, terminating := err.(namespaceTerminatingError); terminating {
+		log.Info(err.Error())
+		return ctrl.Result{RequeueAfter: namespaceRecheck}, r.updateStatus(ctx, operatorConfig, err)
+	}
+	if err != nil {
+		log.Error(err, "Error creating namespace")
+		err := r.updateStatus(ctx, operatorConfig, err)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// The operands are applied while waiting for certificates to be issued,
+	// they are not reported available until then.
+	recheck, err := r.reconcileServingCertificates(ctx, log, operatorConfig)
+	notReady, waiting := err.(certificatesNotReadyError)
+	if err != nil && !waiting {
+		log.Error(err, "Error creating certificates")
+		err := r.updateStatus(ctx, operatorConfig, err)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	for _, o := range rendered {
 		err := r.reconcileOperand(o, ctx, log, operatorConfig)
 		if err != nil {
 			log.Error(err, "Error creating resources")
-			meta.SetStatusCondition(&operatorConfig.Status.Conditions, metav1.Condition{
-				Type:               ReconcileCompleted,
-				Status:             metav1.ConditionFalse,
-				Reason:             ErrorCreatingResources,
-				Message:            fmt.Sprintf("%s", err.Error()),
-				LastTransitionTime: metav1.Time{Time: time.Now()},
-			})
-			err := r.Status().Update(ctx, operatorConfig)
+			err := r.updateStatus(ctx, operatorConfig, err)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
@@ -191,24 +275,47 @@ func (r *OperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	meta.SetStatusCondition(&operatorConfig.Status.Conditions, metav1.Condition{
-		Type:               ReconcileCompleted,
-		Status:             metav1.ConditionTrue,
-		Reason:             ReconcileComplete,
-		Message:            fmt.Sprintf("Reconciled successfully"),
-		LastTransitionTime: metav1.Time{Time: time.Now()},
-	})
-	err := r.Status().Update(ctx, operatorConfig)
+	err = r.pruneExposed(ctx, log, operatorConfig)
+	if err == nil {
+		err = r.pruneNetworkPolicies(ctx, log, operatorConfig)
+	}
+	if err == nil {
+		err = r.pruneTasks(ctx, log, operatorConfig, rendered)
+	}
+	if err == nil {
+		err = r.pruneCertificates(ctx, log, operatorConfig)
+	}
+	if err == nil {
+		err = r.reconcileConsolePluginEnabled(ctx, log, operatorConfig)
+	}
 	if err != nil {
-		return ctrl.Result{}, err
+		log.Error(err, "Error removing resources")
+		err := r.updateStatus(ctx, operatorConfig, err)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// CRD events install the skipped operands, the recheck is a fallback
+	if len(operatorConfig.Status.MissingAPIs) > 0 && (recheck == 0 || recheck > missingAPIRecheck) {
+		recheck = missingAPIRecheck
+	}
+	if waiting {
+		log.Info(notReady.Error())
+		return ctrl.Result{RequeueAfter: recheck}, r.updateStatus(ctx, operatorConfig, notReady)
+	}
+	return ctrl.Result{RequeueAfter: recheck}, r.updateStatus(ctx, operatorConfig, nil)
 }
 
-func (r *OperatorConfigReconciler) cleanUpResources(ctx context.Context) error {
+func (r *OperatorConfigReconciler) cleanUpResources(ctx context.Context, oc *cranev1alpha1.OperatorConfig) error {
+	err := r.disableConsolePlugin(ctx, oc)
+	if err != nil {
+		return err
+	}
+
 	for _, o := range operands {
-		err := r.deleteOperand(o, ctx)
+		err := r.deleteOperand(o, ctx, oc)
 		if err != nil {
 			return err
 		}
@@ -217,37 +324,81 @@ func (r *OperatorConfigReconciler) cleanUpResources(ctx context.Context) error {
 	return nil
 }
 
-func (r *OperatorConfigReconciler) reconcileOperand(o operand, ctx context.Context, log logr.Logger, operatorConfig *cranev1alpha1.OperatorConfig) error {
-	var decoder = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	data, err := getResources(o.path)
+// renderedOperand is an operand with its manifests rendered and the overrides
+// of the OperatorConfig applied
+type renderedOperand struct {
+	operand
+	objs []*unstructured.Unstructured
+}
+
+// renderOperands renders the manifests of all the operands, so they can be
+// validated before any of them is applied.
+func (r *OperatorConfigReconciler) renderOperands(ctx context.Context, operatorConfig *cranev1alpha1.OperatorConfig) ([]renderedOperand, error) {
+	data, err := r.manifestData(ctx, operatorConfig)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	operatorConfig.Status.Platform = data.Platform
+	proxyEnv, err := r.proxyEnv(ctx, operatorConfig)
+	if err != nil {
+		return nil, err
 	}
 
+	var rendered []renderedOperand
+	for _, o := range operands {
+		objs, err := renderManifests(o.path, data)
+		if err != nil {
+			return nil, err
+		}
+		objs = expandTasks(objs, operatorConfig)
+		// Operands are skipped as a whole until all their APIs are served
+		missing, err := missingAPIs(r.restMapper(), objs)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			recordMissingAPIs(&operatorConfig.Status, missing)
+			continue
+		}
+		for _, obj := range objs {
+			// Changed env vars roll out new pods of the Deployments
+			if err := injectEnv(obj, proxyEnv); err != nil {
+				return nil, err
+			}
+			if err := injectTrustedCA(obj, operatorConfig); err != nil {
+				return nil, err
+			}
+			r.applyOverrides(obj, operatorConfig)
+		}
+		rendered = append(rendered, renderedOperand{operand: o, objs: objs})
+	}
+	return rendered, nil
+}
+
+func (r *OperatorConfigReconciler) reconcileOperand(o renderedOperand, ctx context.Context, log logr.Logger, operatorConfig *cranev1alpha1.OperatorConfig) error {
 	reconcilersForGVK := map[string]func(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error{
-		"Deployment":    r.reconcileDeployment,
-		"Service":       r.reconcileService,
-		"ConfigMap":     r.reconcileConfigMap,
-		"ClusterTask":   r.reconcileClusterTask,
-		"ConsolePlugin": r.reconcileConsolePlugin,
+		"Deployment":     r.reconcileDeployment,
+		"Service":        r.reconcileService,
+		"ConfigMap":      r.reconcileConfigMap,
+		"ClusterTask":    r.reconcileClusterTask,
+		"Task":           r.reconcileTask,
+		"ConsolePlugin":  r.reconcileConsolePlugin,
+		"Route":          r.reconcileRoute,
+		"Ingress":        r.reconcileIngress,
+		"NetworkPolicy":  r.reconcileNetworkPolicy,
+		"ServiceAccount": r.reconcileServiceAccount,
+		"Role":           r.reconcileRole,
+		"RoleBinding":    r.reconcileRoleBinding,
 	}
 
-	for _, resource := range data {
-		if len(resource) > 0 {
-			obj := unstructured.Unstructured{}
-			_, gvk, err := decoder.Decode([]byte(resource), nil, &obj)
+	for _, obj := range o.objs {
+		if reconcile, ok := reconcilersForGVK[obj.GetKind()]; ok {
+			err := reconcile(obj, ctx, o.imageFn, log, operatorConfig)
 			if err != nil {
 				return err
 			}
-
-			if reconcile, ok := reconcilersForGVK[gvk.Kind]; ok {
-				err := reconcile(&obj, ctx, o.imageFn, log, operatorConfig)
-				if err != nil {
-					return err
-				}
-			} else {
-				return fmt.Errorf(fmt.Sprintf("Kind %s is not managed by the operator, check input yamls and make sure all the input are in desired state", gvk.Kind))
-			}
+		} else {
+			return fmt.Errorf(fmt.Sprintf("Kind %s is not managed by the operator, check input yamls and make sure all the input are in desired state", obj.GetKind()))
 		}
 	}
 
@@ -263,7 +414,7 @@ func (r *OperatorConfigReconciler) reconcileDeployment(resource *unstructured.Un
 	}
 
 	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Namespace: obj.Namespace}}
-	op, err := controllerutil.CreateOrPatch(context.TODO(), r.Client, deploy, func() error {
+	op, err := r.createOrPatch(ctx, oc, deploy, func() error {
 		if deploy.ObjectMeta.CreationTimestamp.IsZero() {
 			deploy.Spec.Selector = obj.Spec.Selector
 		}
@@ -281,12 +432,29 @@ func (r *OperatorConfigReconciler) reconcileDeployment(resource *unstructured.Un
 			deploy.Annotations = obj.Annotations
 		}
 
-		// Override each of the images in the deployment spec.
-		// If, in the future, we have multiple image in a single component we will
-		// need a new approach.
-		for i := range deploy.Spec.Template.Spec.Containers {
-			deploy.Spec.Template.Spec.Containers[i].Image = imageFn()
+		// Override each of the images in the deployment spec, containers run
+		// the image of the operand unless they select an image key.
+		podSpec := &deploy.Spec.Template.Spec
+		for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+			for i := range containers {
+				image, err := imageFor(obj.Annotations, containers[i].Name, imageFn)
+				if err != nil {
+					return err
+				}
+				containers[i].Image = rewriteImage(oc, image)
+			}
 		}
+		applyPodPullSettings(podSpec, oc)
+
+		// Roll out new pods when the referenced configuration changes
+		hash, err := r.configHash(ctx, deploy.Namespace, podSpec)
+		if err != nil {
+			return err
+		}
+		if deploy.Spec.Template.Annotations == nil {
+			deploy.Spec.Template.Annotations = map[string]string{}
+		}
+		deploy.Spec.Template.Annotations[ConfigHashAnnotation] = hash
 		return nil
 	})
 	if err != nil {
@@ -307,7 +475,7 @@ func (r *OperatorConfigReconciler) reconcileService(resource *unstructured.Unstr
 	}
 
 	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
-	op, err := controllerutil.CreateOrPatch(context.TODO(), r.Client, service, func() error {
+	op, err := r.createOrPatch(ctx, oc, service, func() error {
 		if service.ObjectMeta.CreationTimestamp.IsZero() {
 			service.Spec.Selector = obj.Spec.Selector
 		}
@@ -344,13 +512,17 @@ func (r *OperatorConfigReconciler) reconcileConfigMap(resource *unstructured.Uns
 	}
 
 	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
-	op, err := controllerutil.CreateOrPatch(context.TODO(), r.Client, configMap, func() error {
+	op, err := r.createOrPatch(ctx, oc, configMap, func() error {
 		err = controllerutil.SetControllerReference(oc, configMap, r.Scheme)
 		if err != nil {
 			return err
 		}
 
-		configMap.Data = obj.Data
+		// The data of ConfigMaps labeled for injection is written by the
+		// cluster network operator
+		if obj.Labels[InjectTrustedCABundleLabel] != "true" || len(obj.Data) > 0 {
+			configMap.Data = obj.Data
+		}
 		if len(obj.Labels) > 0 {
 			configMap.Labels = obj.Labels
 		}
@@ -377,7 +549,7 @@ func (r *OperatorConfigReconciler) reconcileClusterTask(resource *unstructured.U
 	}
 
 	clusterTask := &pipelinev1beta1.ClusterTask{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
-	op, err := controllerutil.CreateOrPatch(context.TODO(), r.Client, clusterTask, func() error {
+	op, err := r.createOrPatch(ctx, oc, clusterTask, func() error {
 		err = controllerutil.SetControllerReference(oc, clusterTask, r.Scheme)
 		if err != nil {
 			return err
@@ -390,11 +562,7 @@ func (r *OperatorConfigReconciler) reconcileClusterTask(resource *unstructured.U
 		if len(obj.Annotations) > 0 {
 			clusterTask.Annotations = obj.Annotations
 		}
-
-		for i := range clusterTask.Spec.Steps {
-			clusterTask.Spec.Steps[i].Image = imageFn()
-		}
-		return nil
+		return prepareTaskSpec(&clusterTask.Spec, obj.Annotations, imageFn, oc)
 	})
 	if err != nil {
 		return err
@@ -406,6 +574,10 @@ func (r *OperatorConfigReconciler) reconcileClusterTask(resource *unstructured.U
 }
 
 func (r *OperatorConfigReconciler) reconcileConsolePlugin(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	if resource.GroupVersionKind() == consolePluginV1GVK {
+		return r.reconcileConsolePluginV1(resource, ctx, log, oc)
+	}
+
 	var obj consolev1alpha1.ConsolePlugin
 	err := runtime.DefaultUnstructuredConverter.
 		FromUnstructured(resource.UnstructuredContent(), &obj)
@@ -414,7 +586,7 @@ func (r *OperatorConfigReconciler) reconcileConsolePlugin(resource *unstructured
 	}
 
 	consolePlugin := &consolev1alpha1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
-	op, err := controllerutil.CreateOrPatch(context.TODO(), r.Client, consolePlugin, func() error {
+	op, err := r.createOrPatch(ctx, oc, consolePlugin, func() error {
 		err = controllerutil.SetControllerReference(oc, consolePlugin, r.Scheme)
 		if err != nil {
 			return err
@@ -438,39 +610,39 @@ func (r *OperatorConfigReconciler) reconcileConsolePlugin(resource *unstructured
 	return nil
 }
 
-func (r *OperatorConfigReconciler) deleteOperand(o operand, ctx context.Context) error {
-	var decoder = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	data, err := getResources(o.path)
+func (r *OperatorConfigReconciler) deleteOperand(o operand, ctx context.Context, oc *cranev1alpha1.OperatorConfig) error {
+	data, err := r.manifestData(ctx, oc)
 	if err != nil {
 		return err
 	}
+	objs, err := renderManifests(o.path, data)
+	if err != nil {
+		return err
+	}
+	objs = expandTasks(objs, oc)
 
-	for _, resource := range data {
-		if len(resource) > 0 {
-			obj := &unstructured.Unstructured{}
-			_, _, err := decoder.Decode([]byte(resource), nil, obj)
-			if err != nil {
-				return err
-			}
-
-			if err = r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, obj); err != nil {
-				if !errors.IsNotFound(err) {
-					return err
-				}
+	for _, obj := range objs {
+		if err = r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, obj); err != nil {
+			// Nothing was created for APIs that are not served
+			if meta.IsNoMatchError(err) {
+				continue
 			}
-
-			if controllerutil.ContainsFinalizer(obj, Finalizer) {
-				controllerutil.RemoveFinalizer(obj, Finalizer)
-				if err = r.Update(ctx, obj); err != nil {
-					return err
-				}
+			if !errors.IsNotFound(err) {
+				return err
 			}
+		}
 
-			err = r.Delete(ctx, obj)
-			if err != nil && !(errors.IsGone(err) || errors.IsNotFound(err)) {
+		if controllerutil.ContainsFinalizer(obj, Finalizer) {
+			controllerutil.RemoveFinalizer(obj, Finalizer)
+			if err = r.Update(ctx, obj); err != nil {
 				return err
 			}
 		}
+
+		err = r.Delete(ctx, obj)
+		if err != nil && !(errors.IsGone(err) || errors.IsNotFound(err)) {
+			return err
+		}
 	}
 
 	return nil
@@ -478,12 +650,39 @@ func (r *OperatorConfigReconciler) deleteOperand(o operand, ctx context.Context)
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *OperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&cranev1alpha1.OperatorConfig{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&corev1.Service{}).
-		Owns(&corev1.ConfigMap{}).
-		Owns(&consolev1alpha1.ConsolePlugin{}).
-		Owns(&pipelinev1beta1.ClusterTask{}).
-		Complete(r)
+	r.mapper = newMissingAPIMapper(mgr.GetRESTMapper())
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&cranev1alpha1.OperatorConfig{}, specChanged()).
+		Owns(&appsv1.Deployment{}, specChanged()).
+		Owns(&corev1.Service{}, contentChanged()).
+		Owns(&corev1.ConfigMap{}, contentChanged()).
+		Owns(&corev1.Secret{}, contentChanged()).
+		Owns(&networkingv1.Ingress{}, specChanged()).
+		Owns(&networkingv1.NetworkPolicy{}, specChanged()).
+		Owns(&corev1.ServiceAccount{}, contentChanged()).
+		Owns(&rbacv1.Role{}, contentChanged()).
+		Owns(&rbacv1.RoleBinding{}, contentChanged()).
+		// The install namespace is labeled, and recreated once deleted
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, enqueueOperatorConfig(), builder.WithPredicates(predicate.NewPredicateFuncs(installNamespace))).
+		// Certificates rotated by the service CA or cert-manager roll out new operand pods
+		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueOperatorConfig(), builder.WithPredicates(predicate.NewPredicateFuncs(servingCertSecret))).
+		// New CRDs of optional APIs start their watches and operands
+		Watches(&source.Kind{Type: crdMetadata()}, r.mapper.enqueueOnCRD(), builder.WithPredicates(predicate.NewPredicateFuncs(optionalCRD))).
+		Build(r)
+	if err != nil {
+		return err
+	}
+
+	// The optional APIs, like ClusterTasks or ConsolePlugins, are watched
+	// once they are served
+	r.watches = newDynamicWatches(c, r.mapper)
+	return r.watches.sync(mgr.GetLogger())
+}
+
+// enqueueOperatorConfig reconciles the OperatorConfig on events of cluster
+// objects the operands are configured from.
+func enqueueOperatorConfig() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: OwnerConfigName}}}
+	})
 }
@@ -1,17 +1,249 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
 	"io/ioutil"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 )
 
-func getResources(path string) ([]string, error) {
-	var data []byte
+// manifestData is available to the operand manifests, which are rendered as
+// Go templates. The {% %} delimiters are used since {{ }} appears in the
+// scripts of the ClusterTasks.
+type manifestData struct {
+	// Spec of the OperatorConfig
+	Spec cranev1alpha1.OperatorConfigSpec
+	// Namespace the operands are installed in
+	Namespace string
+	// Capabilities detected on the cluster
+	Capabilities Capabilities
+	// Images resolved for each image key, e.g. crane-runner
+	Images map[string]string
+	// TLS settings of the operand servers
+	TLS TLSConfig
+	// Platform the operands are installed on, OpenShift or Kubernetes
+	Platform cranev1alpha1.Platform
+	// CertificateProvider of the serving certificates, e.g. ServiceCA
+	CertificateProvider cranev1alpha1.CertificateProvider
+}
+
+func (r *OperatorConfigReconciler) manifestData(ctx context.Context, oc *cranev1alpha1.OperatorConfig) (manifestData, error) {
+	// Rewrites are recorded where the images are deployed, not every image
+	// key is used by the operands of the platform.
+	images := resolvedImages()
+	for key, image := range images {
+		images[key] = mirrorImage(image, oc.Spec.ImageMirrors)
+	}
+
+	tls, err := r.tlsConfig(ctx, oc)
+	if err != nil {
+		return manifestData{}, err
+	}
+
+	capabilities := r.detectCapabilities()
+	platform := platformFor(oc, capabilities)
+	return manifestData{
+		Spec:                oc.Spec,
+		Namespace:           InstallNamespace,
+		Capabilities:        capabilities,
+		Images:              images,
+		TLS:                 tls,
+		Platform:            platform,
+		CertificateProvider: certificateProviderFor(oc, platform),
+	}, nil
+}
+
+// platformFor returns the platform set in the spec, or the detected one.
+func platformFor(oc *cranev1alpha1.OperatorConfig, capabilities Capabilities) cranev1alpha1.Platform {
+	if oc.Spec.Platform != "" && oc.Spec.Platform != cranev1alpha1.PlatformAuto {
+		return oc.Spec.Platform
+	}
+	if capabilities.OpenShift {
+		return cranev1alpha1.PlatformOpenShift
+	}
+	return cranev1alpha1.PlatformKubernetes
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+func getResources(path string, data manifestData) ([]string, error) {
+	var raw []byte
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).
+		Delims("{%", "%}").
+		Option("missingkey=error").
+		Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	rendered := &bytes.Buffer{}
+	if err := tmpl.Execute(rendered, data); err != nil {
+		return nil, err
+	}
+
+	// Only split on separator lines, PEM bundles contain dashes too
+	return documentSeparator.Split(rendered.String(), -1), nil
+}
 
-	data, err := ioutil.ReadFile(path)
+// renderManifests renders the manifests at path and decodes each of the
+// resources in it.
+func renderManifests(path string, data manifestData) ([]*unstructured.Unstructured, error) {
+	var decoder = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
+	resources, err := getResources(path, data)
 	if err != nil {
 		return nil, err
 	}
 
-	return strings.Split(string(data), "---"), nil
+	var objs []*unstructured.Unstructured
+	for _, resource := range resources {
+		if len(strings.TrimSpace(resource)) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if _, _, err := decoder.Decode([]byte(resource), nil, obj); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// containerFields returns the paths of the container lists of a rendered
+// resource, the containers of Deployments and the steps of ClusterTasks and
+// Tasks.
+func containerFields(kind string) [][]string {
+	switch kind {
+	case "Deployment":
+		return [][]string{
+			{"spec", "template", "spec", "initContainers"},
+			{"spec", "template", "spec", "containers"},
+		}
+	case "ClusterTask", "Task":
+		return [][]string{{"spec", "steps"}}
+	}
+	return nil
+}
+
+// volumesField returns the path of the volumes of a rendered resource.
+func volumesField(kind string) []string {
+	switch kind {
+	case "Deployment":
+		return []string{"spec", "template", "spec", "volumes"}
+	case "ClusterTask", "Task":
+		return []string{"spec", "volumes"}
+	}
+	return nil
+}
+
+// injectEnv sets the env vars on every container of a rendered resource,
+// replacing the variables of the same name.
+func injectEnv(resource *unstructured.Unstructured, env []corev1.EnvVar) error {
+	if len(env) == 0 {
+		return nil
+	}
+
+	var items []interface{}
+	for _, v := range env {
+		items = append(items, map[string]interface{}{"name": v.Name, "value": v.Value})
+	}
+	return updateContainers(resource, func(container map[string]interface{}) error {
+		return mergeNamed(container, items, "env")
+	})
+}
+
+// injectVolume adds the volume to a rendered resource and mounts it into
+// every container, replacing the volume and mounts of the same name.
+func injectVolume(resource *unstructured.Unstructured, volume corev1.Volume, mount corev1.VolumeMount) error {
+	field := volumesField(resource.GetKind())
+	if field == nil {
+		return nil
+	}
+
+	volumes, err := toUnstructuredItems(&volume)
+	if err != nil {
+		return err
+	}
+	if err := mergeNamed(resource.Object, volumes, field...); err != nil {
+		return err
+	}
+
+	mounts, err := toUnstructuredItems(&mount)
+	if err != nil {
+		return err
+	}
+	return updateContainers(resource, func(container map[string]interface{}) error {
+		return mergeNamed(container, mounts, "volumeMounts")
+	})
+}
+
+// updateContainers calls update with every container of a rendered resource.
+func updateContainers(resource *unstructured.Unstructured, update func(container map[string]interface{}) error) error {
+	for _, field := range containerFields(resource.GetKind()) {
+		containers, found, err := unstructured.NestedSlice(resource.Object, field...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, container := range containers {
+			if err := update(container.(map[string]interface{})); err != nil {
+				return err
+			}
+		}
+		if err := unstructured.SetNestedSlice(resource.Object, containers, field...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeNamed appends the items to the list at the field of obj, replacing the
+// items of the same name.
+func mergeNamed(obj map[string]interface{}, items []interface{}, field ...string) error {
+	current, _, err := unstructured.NestedSlice(obj, field...)
+	if err != nil {
+		return err
+	}
+
+	names := map[interface{}]bool{}
+	for _, item := range items {
+		names[item.(map[string]interface{})["name"]] = true
+	}
+	var merged []interface{}
+	for _, item := range current {
+		if !names[item.(map[string]interface{})["name"]] {
+			merged = append(merged, item)
+		}
+	}
+	return unstructured.SetNestedSlice(obj, append(merged, items...), field...)
+}
+
+// toUnstructuredItems converts API structs, like volumes, to the
+// representation used in unstructured objects.
+func toUnstructuredItems(objs ...interface{}) ([]interface{}, error) {
+	var items []interface{}
+	for _, obj := range objs {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
 }
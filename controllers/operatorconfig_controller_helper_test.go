@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Manifests", func() {
+	var data manifestData
+
+	BeforeEach(func() {
+		data = manifestData{
+			Spec: cranev1alpha1.OperatorConfigSpec{
+				Expose: []cranev1alpha1.ExposeConfig{{Component: "proxy"}},
+			},
+			Namespace: "crane-test",
+			Images:    resolvedImages(),
+			TLS:       tlsConfigFor(nil),
+			Platform:  cranev1alpha1.PlatformOpenShift,
+
+			CertificateProvider: cranev1alpha1.CertificateProviderServiceCA,
+		}
+	})
+
+	It("renders every operand manifest", func() {
+		for _, o := range operands {
+			objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", o.path), data)
+			Expect(err).NotTo(HaveOccurred(), o.path)
+			Expect(objs).NotTo(BeEmpty(), o.path)
+
+			for _, obj := range objs {
+				if obj.GetNamespace() != "" {
+					Expect(obj.GetNamespace()).To(Equal("crane-test"), obj.GetName())
+				}
+			}
+		}
+	})
+
+	It("leaves out the OpenShift resources on Kubernetes", func() {
+		data.Platform = cranev1alpha1.PlatformKubernetes
+		data.CertificateProvider = cranev1alpha1.CertificateProviderSelfSigned
+
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-ui-plugin.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+
+		for _, path := range []string{"crane-reverse-proxy.yaml", "crane-secret-service.yaml"} {
+			objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", path), data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objs).To(HaveLen(5))
+			for _, obj := range objs {
+				Expect(obj.GetAnnotations()).NotTo(HaveKey("service.beta.openshift.io/serving-cert-secret-name"), path)
+			}
+		}
+	})
+
+	It("detects the platform unless set in the spec", func() {
+		oc := &cranev1alpha1.OperatorConfig{}
+		Expect(platformFor(oc, Capabilities{OpenShift: true})).To(Equal(cranev1alpha1.PlatformOpenShift))
+		Expect(platformFor(oc, Capabilities{})).To(Equal(cranev1alpha1.PlatformKubernetes))
+
+		oc.Spec.Platform = cranev1alpha1.PlatformKubernetes
+		Expect(platformFor(oc, Capabilities{OpenShift: true})).To(Equal(cranev1alpha1.PlatformKubernetes))
+	})
+
+	It("injects env vars into every container", func() {
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-reverse-proxy.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		env := []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"}}
+
+		for _, obj := range objs {
+			if obj.GetKind() != "Deployment" {
+				continue
+			}
+			Expect(injectEnv(obj, env)).To(Succeed())
+			Expect(injectEnv(obj, env)).To(Succeed())
+
+			deploy := &appsv1.Deployment{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deploy)).To(Succeed())
+			for _, container := range deploy.Spec.Template.Spec.Containers {
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GIN_MODE", Value: "release"}))
+				Expect(container.Env[len(container.Env)-1]).To(Equal(env[0]))
+				Expect(container.Env[:len(container.Env)-1]).NotTo(ContainElement(env[0]))
+			}
+		}
+	})
+})
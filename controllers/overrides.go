@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// Event reasons
+const (
+	InvalidOverride = "InvalidOverride"
+)
+
+// applyOverrides applies the overrides of the OperatorConfig targeting the
+// rendered resource. Overrides that fail are skipped and reported in the
+// status, the resource is still applied without them.
+func (r *OperatorConfigReconciler) applyOverrides(resource *unstructured.Unstructured, oc *cranev1alpha1.OperatorConfig) {
+	gvk := resource.GroupVersionKind()
+	for _, override := range oc.Spec.Overrides {
+		if override.Group != gvk.Group || override.Kind != gvk.Kind || override.Name != resource.GetName() {
+			continue
+		}
+
+		patched, err := r.patchResource(resource, override)
+		if err != nil {
+			oc.Status.InvalidOverrides = append(oc.Status.InvalidOverrides, cranev1alpha1.OverrideError{
+				Group: override.Group,
+				Kind:  override.Kind,
+				Name:  override.Name,
+				Error: err.Error(),
+			})
+			r.event(oc, corev1.EventTypeWarning, InvalidOverride, "Override for %s %s was not applied: %v", override.Kind, override.Name, err)
+			continue
+		}
+		resource.Object = patched.Object
+	}
+}
+
+// patchResource returns a copy of the resource with the override applied.
+func (r *OperatorConfigReconciler) patchResource(resource *unstructured.Unstructured, override cranev1alpha1.Override) (*unstructured.Unstructured, error) {
+	original, err := resource.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	patch, err := yaml.YAMLToJSON([]byte(override.Patch))
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	var patched []byte
+	switch override.Type {
+	case cranev1alpha1.JSONPatch:
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON patch: %w", err)
+		}
+		patched, err = p.Apply(original)
+		if err != nil {
+			return nil, err
+		}
+	case cranev1alpha1.MergePatch:
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, err
+		}
+	case cranev1alpha1.StrategicMergePatch, "":
+		// Strategic merge patches need the patch strategies of the Go type.
+		dataStruct, err := r.Scheme.New(resource.GroupVersionKind())
+		if err != nil {
+			return nil, fmt.Errorf("strategic merge patches are not supported for %s, use a Merge or JSON patch: %w", resource.GetKind(), err)
+		}
+		patched, err = strategicpatch.StrategicMergePatch(original, patch, dataStruct)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", override.Type)
+	}
+
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return nil, err
+	}
+	if result.GroupVersionKind() != resource.GroupVersionKind() || result.GetName() != resource.GetName() || result.GetNamespace() != resource.GetNamespace() {
+		return nil, fmt.Errorf("patches must not change the apiVersion, kind, name or namespace")
+	}
+	if err := checkImagesUnchanged(resource, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkImagesUnchanged rejects patches setting the image of a container or
+// step. Images are resolved from the image keys, mirrored and checked for
+// digests by the operator, an image set by a patch would be replaced.
+func checkImagesUnchanged(resource, patched *unstructured.Unstructured) error {
+	for _, field := range containerFields(resource.GetKind()) {
+		original, _, _ := unstructured.NestedSlice(resource.Object, field...)
+		images := map[string]string{}
+		for _, container := range original {
+			name, _, _ := unstructured.NestedString(container.(map[string]interface{}), "name")
+			images[name], _, _ = unstructured.NestedString(container.(map[string]interface{}), "image")
+		}
+
+		containers, _, _ := unstructured.NestedSlice(patched.Object, field...)
+		for _, container := range containers {
+			name, _, _ := unstructured.NestedString(container.(map[string]interface{}), "name")
+			image, _, _ := unstructured.NestedString(container.(map[string]interface{}), "image")
+			if image != "" && image != images[name] {
+				return fmt.Errorf("patches must not set the image of %s, select an image key with the %s%s annotation instead", name, ImageKeyAnnotationPrefix, name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const proxyDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: proxy
+  namespace: openshift-migration-toolkit
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: quay.io/konveyor/crane-reverse-proxy
+        env:
+        - name: GIN_MODE
+          value: release
+`
+
+var _ = Describe("Overrides", func() {
+	var r *OperatorConfigReconciler
+	var oc *cranev1alpha1.OperatorConfig
+	var resource *unstructured.Unstructured
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc = &cranev1alpha1.OperatorConfig{}
+		resource = &unstructured.Unstructured{}
+		_, _, err := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).Decode([]byte(proxyDeployment), nil, resource)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	containerEnv := func() []interface{} {
+		containers, _, err := unstructured.NestedSlice(resource.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		env, _, err := unstructured.NestedSlice(containers[0].(map[string]interface{}), "env")
+		Expect(err).NotTo(HaveOccurred())
+		return env
+	}
+
+	It("merges strategic merge patches by list keys", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        env:
+        - name: DEBUG
+          value: "true"
+`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(BeEmpty())
+		Expect(containerEnv()).To(HaveLen(2))
+	})
+
+	It("applies JSON patches", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Type:  cranev1alpha1.JSONPatch,
+			Patch: `[{"op": "replace", "path": "/spec/template/spec/containers/0/env/0/value", "value": "debug"}]`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(BeEmpty())
+		Expect(containerEnv()[0]).To(HaveKeyWithValue("value", "debug"))
+	})
+
+	It("ignores overrides targeting other resources", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Type:  cranev1alpha1.JSONPatch,
+			Patch: `[{"op": "remove", "path": "/spec"}]`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(BeEmpty())
+		Expect(containerEnv()).To(HaveLen(1))
+	})
+
+	It("reports invalid patches and leaves the resource untouched", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Type:  cranev1alpha1.JSONPatch,
+			Patch: `[{"op": "replace", "path": "/spec/missing/field", "value": "x"}]`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(HaveLen(1))
+		Expect(oc.Status.InvalidOverrides[0].Name).To(Equal("proxy"))
+		Expect(containerEnv()).To(HaveLen(1))
+	})
+
+	It("rejects patches setting images", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Type:  cranev1alpha1.MergePatch,
+			Patch: `{"metadata": {"labels": {"team": "a"}}}`,
+		}, {
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: quay.io/example/proxy:debug
+`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(HaveLen(1))
+		Expect(oc.Status.InvalidOverrides[0].Error).To(ContainSubstring("image.crane.konveyor.io/proxy"))
+		Expect(resource.GetLabels()).To(HaveKeyWithValue("team", "a"))
+		containers, _, err := unstructured.NestedSlice(resource.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers[0]).NotTo(HaveKeyWithValue("image", "quay.io/example/proxy:debug"))
+	})
+
+	It("rejects patches adding containers with an image", func() {
+		oc.Spec.Overrides = []cranev1alpha1.Override{{
+			Group: "apps",
+			Kind:  "Deployment",
+			Name:  "proxy",
+			Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: metrics-exporter
+        image: quay.io/example/metrics-exporter
+`,
+		}}
+		r.applyOverrides(resource, oc)
+
+		Expect(oc.Status.InvalidOverrides).To(HaveLen(1))
+	})
+})
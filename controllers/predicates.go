@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// specChanged lets through updates that touch the spec (tracked by
+// metadata.generation), labels or annotations. Status-only writes, such as the
+// Deployment controller's availability heartbeats or our own OperatorConfig
+// status updates, are dropped.
+func specChanged() builder.Predicates {
+	return builder.WithPredicates(specChangedPredicate())
+}
+
+func specChangedPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.LabelChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+	)
+}
+
+// contentChanged is the equivalent of specChanged for kinds that never bump
+// metadata.generation, like Services and ConfigMaps.
+func contentChanged() builder.Predicates {
+	return builder.WithPredicates(contentChangedPredicate{})
+}
+
+// contentChangedPredicate passes update events when anything other than the
+// status or server managed metadata of the object changed.
+type contentChangedPredicate struct {
+	predicate.Funcs
+}
+
+func (contentChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	oldContent, err := reconciledContent(e.ObjectOld)
+	if err != nil {
+		return true
+	}
+	newContent, err := reconciledContent(e.ObjectNew)
+	if err != nil {
+		return true
+	}
+
+	return !equality.Semantic.DeepEqual(oldContent, newContent)
+}
+
+// statusChangedPredicate passes update events changing one of the fields
+// of the status, for kinds whose controller only reports through the status,
+// like cert-manager Certificates.
+type statusChangedPredicate struct {
+	predicate.Funcs
+	fields []string
+}
+
+func (p statusChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	oldContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.ObjectOld)
+	if err != nil {
+		return true
+	}
+	newContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.ObjectNew)
+	if err != nil {
+		return true
+	}
+
+	for _, field := range p.fields {
+		oldValue, _, _ := unstructured.NestedFieldNoCopy(oldContent, "status", field)
+		newValue, _, _ := unstructured.NestedFieldNoCopy(newContent, "status", field)
+		if !equality.Semantic.DeepEqual(oldValue, newValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconciledContent returns a copy of the object without the fields the
+// operator never reconciles.
+func reconciledContent(obj client.Object) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := obj.(runtime.Unstructured); ok {
+		// The converter returns the content of unstructured objects as is,
+		// which is shared with the cache
+		content = runtime.DeepCopyJSON(content)
+	}
+
+	unstructured.RemoveNestedField(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	return content, nil
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Predicates", func() {
+	var svc *corev1.Service
+
+	BeforeEach(func() {
+		svc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "proxy",
+				Namespace:       "default",
+				ResourceVersion: "1",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "port-8443", Port: 8443}},
+			},
+		}
+	})
+
+	It("ignores status only updates", func() {
+		updated := svc.DeepCopy()
+		updated.ResourceVersion = "2"
+		updated.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+
+		Expect(contentChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: svc, ObjectNew: updated})).To(BeFalse())
+	})
+
+	It("passes spec updates", func() {
+		updated := svc.DeepCopy()
+		updated.ResourceVersion = "2"
+		updated.Spec.Ports[0].Port = 9443
+
+		Expect(contentChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: svc, ObjectNew: updated})).To(BeTrue())
+	})
+
+	Context("with cert-manager Certificates", func() {
+		var certificate *unstructured.Unstructured
+		var certificateStatusChanged statusChangedPredicate
+
+		BeforeEach(func() {
+			certificate = unstructuredOf(certificateGVK)
+			certificate.SetName("crane-proxy")
+			certificate.SetNamespace(InstallNamespace)
+			certificate.SetResourceVersion("1")
+			Expect(unstructured.SetNestedField(certificate.Object, "2026-01-01T00:00:00Z", "status", "notAfter")).To(Succeed())
+			certificateStatusChanged = statusChangedPredicate{fields: []string{"conditions", "notAfter"}}
+		})
+
+		It("passes issuance", func() {
+			updated := certificate.DeepCopy()
+			updated.SetResourceVersion("2")
+			Expect(unstructured.SetNestedSlice(updated.Object, []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}, "status", "conditions")).To(Succeed())
+
+			Expect(contentChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: certificate, ObjectNew: updated})).To(BeFalse())
+			Expect(certificateStatusChanged.Update(event.UpdateEvent{ObjectOld: certificate, ObjectNew: updated})).To(BeTrue())
+		})
+
+		It("passes renewals", func() {
+			updated := certificate.DeepCopy()
+			updated.SetResourceVersion("2")
+			Expect(unstructured.SetNestedField(updated.Object, "2026-04-01T00:00:00Z", "status", "notAfter")).To(Succeed())
+
+			Expect(certificateStatusChanged.Update(event.UpdateEvent{ObjectOld: certificate, ObjectNew: updated})).To(BeTrue())
+		})
+
+		It("ignores other status updates", func() {
+			updated := certificate.DeepCopy()
+			updated.SetResourceVersion("2")
+			Expect(unstructured.SetNestedField(updated.Object, int64(1), "status", "revision")).To(Succeed())
+
+			Expect(certificateStatusChanged.Update(event.UpdateEvent{ObjectOld: certificate, ObjectNew: updated})).To(BeFalse())
+		})
+
+		It("leaves the status of the compared objects alone", func() {
+			updated := certificate.DeepCopy()
+			updated.SetResourceVersion("2")
+
+			contentChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: certificate, ObjectNew: updated})
+			Expect(updated.Object).To(HaveKey("status"))
+			Expect(certificate.Object).To(HaveKey("status"))
+		})
+	})
+})
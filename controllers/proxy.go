@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// clusterProxyName is the name of the cluster wide config.openshift.io Proxy
+const clusterProxyName = "cluster"
+
+var proxyGVK = configv1.GroupVersion.WithKind("Proxy")
+
+// proxyEnv returns the proxy env vars of the operands. spec.proxy takes
+// precedence over the cluster wide Proxy, which is only read on clusters
+// serving it.
+func (r *OperatorConfigReconciler) proxyEnv(ctx context.Context, oc *cranev1alpha1.OperatorConfig) ([]corev1.EnvVar, error) {
+	config := cranev1alpha1.ProxyConfig{}
+	switch {
+	case oc.Spec.Proxy != nil:
+		config = *oc.Spec.Proxy
+	case served(r.restMapper(), proxyGVK):
+		proxy := &configv1.Proxy{}
+		err := r.Get(ctx, types.NamespacedName{Name: clusterProxyName}, proxy)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		// The status holds the proxy settings in effect
+		config.HTTPProxy = proxy.Status.HTTPProxy
+		config.HTTPSProxy = proxy.Status.HTTPSProxy
+		config.NoProxy = proxy.Status.NoProxy
+	}
+
+	var env []corev1.EnvVar
+	for _, v := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: config.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: config.HTTPSProxy},
+		{Name: "NO_PROXY", Value: config.NoProxy},
+	} {
+		if v.Value != "" {
+			env = append(env, v)
+		}
+	}
+	return env, nil
+}
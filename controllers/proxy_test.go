@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Proxy", func() {
+	var r *OperatorConfigReconciler
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc = &cranev1alpha1.OperatorConfig{}
+	})
+
+	It("uses the proxy of the spec", func() {
+		oc.Spec.Proxy = &cranev1alpha1.ProxyConfig{
+			HTTPSProxy: "http://proxy.example.com:3128",
+			NoProxy:    ".cluster.local,.svc",
+		}
+
+		env, err := r.proxyEnv(context.TODO(), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env).To(Equal([]corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+			{Name: "NO_PROXY", Value: ".cluster.local,.svc"},
+		}))
+	})
+
+	It("disables the proxy with an empty spec", func() {
+		oc.Spec.Proxy = &cranev1alpha1.ProxyConfig{}
+
+		env, err := r.proxyEnv(context.TODO(), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env).To(BeEmpty())
+	})
+})
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func (r *OperatorConfigReconciler) reconcileServiceAccount(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj corev1.ServiceAccount
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, serviceAccount, func() error {
+		err = controllerutil.SetControllerReference(oc, serviceAccount, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		// The token and pull secrets are added by the cluster, they are
+		// left as they are
+		if len(obj.Labels) > 0 {
+			serviceAccount.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			serviceAccount.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("ServiceAccount successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+func (r *OperatorConfigReconciler) reconcileRole(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj rbacv1.Role
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, role, func() error {
+		err = controllerutil.SetControllerReference(oc, role, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		role.Rules = obj.Rules
+		if len(obj.Labels) > 0 {
+			role.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			role.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("Role successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+func (r *OperatorConfigReconciler) reconcileRoleBinding(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj rbacv1.RoleBinding
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, roleBinding, func() error {
+		// The role of a binding is immutable
+		if roleBinding.ObjectMeta.CreationTimestamp.IsZero() {
+			roleBinding.RoleRef = obj.RoleRef
+		}
+
+		err = controllerutil.SetControllerReference(oc, roleBinding, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		roleBinding.Subjects = obj.Subjects
+		if len(obj.Labels) > 0 {
+			roleBinding.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			roleBinding.Annotations = obj.Annotations
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("RoleBinding successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
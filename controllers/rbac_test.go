@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("RBAC", func() {
+	var (
+		r  *OperatorConfigReconciler
+		oc *cranev1alpha1.OperatorConfig
+	)
+
+	BeforeEach(func() {
+		r = &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc = &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+	})
+
+	AfterEach(func() {
+		meta := metav1.ObjectMeta{Name: "secret-service", Namespace: InstallNamespace}
+		for _, obj := range []client.Object{&corev1.ServiceAccount{ObjectMeta: meta}, &rbacv1.Role{ObjectMeta: meta}, &rbacv1.RoleBinding{ObjectMeta: meta}} {
+			Expect(client.IgnoreNotFound(c.Delete(context.TODO(), obj))).To(Succeed())
+		}
+	})
+
+	toUnstructured := func(obj interface{}) *unstructured.Unstructured {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		Expect(err).NotTo(HaveOccurred())
+		return &unstructured.Unstructured{Object: content}
+	}
+
+	It("keeps the secrets added to ServiceAccounts", func() {
+		serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret-service",
+			Namespace: InstallNamespace,
+			Labels:    map[string]string{"app": "crane"},
+		}}
+		resource := toUnstructured(serviceAccount)
+
+		Expect(r.reconcileServiceAccount(resource, context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(serviceAccount), serviceAccount)).To(Succeed())
+		Expect(metav1.IsControlledBy(serviceAccount, oc)).To(BeTrue())
+
+		serviceAccount.Secrets = []corev1.ObjectReference{{Name: "secret-service-token-abcde"}}
+		Expect(c.Update(context.TODO(), serviceAccount)).To(Succeed())
+
+		Expect(r.reconcileServiceAccount(resource, context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(serviceAccount), serviceAccount)).To(Succeed())
+		Expect(serviceAccount.Secrets).To(HaveLen(1))
+	})
+
+	It("corrects the rules of Roles and the subjects of RoleBindings", func() {
+		role := &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-service", Namespace: InstallNamespace},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}},
+		}
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-service", Namespace: InstallNamespace},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "secret-service"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "secret-service", Namespace: InstallNamespace}},
+		}
+		Expect(r.reconcileRole(toUnstructured(role), context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(r.reconcileRoleBinding(toUnstructured(roleBinding), context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		live := &rbacv1.Role{}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(role), live)).To(Succeed())
+		live.Rules[0].Verbs = []string{"*"}
+		Expect(c.Update(context.TODO(), live)).To(Succeed())
+		liveBinding := &rbacv1.RoleBinding{}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(roleBinding), liveBinding)).To(Succeed())
+		liveBinding.Subjects = append(liveBinding.Subjects, rbacv1.Subject{Kind: "ServiceAccount", Name: "default", Namespace: "default"})
+		Expect(c.Update(context.TODO(), liveBinding)).To(Succeed())
+
+		Expect(r.reconcileRole(toUnstructured(role), context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+		Expect(r.reconcileRoleBinding(toUnstructured(roleBinding), context.TODO(), nil, log.FromContext(context.TODO()), oc)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(role), live)).To(Succeed())
+		Expect(live.Rules).To(Equal(role.Rules))
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(roleBinding), liveBinding)).To(Succeed())
+		Expect(liveBinding.Subjects).To(Equal(roleBinding.Subjects))
+		Expect(liveBinding.RoleRef).To(Equal(roleBinding.RoleRef))
+	})
+})
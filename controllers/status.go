@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// invalidNameError is returned when the OperatorConfig is not the singleton
+// the operator manages.
+type invalidNameError struct {
+	name string
+}
+
+func (e invalidNameError) Error() string {
+	return fmt.Sprintf("Invalid name (%s): Only one OperatorConfig supported per cluster and must be named '%s'", e.name, OwnerConfigName)
+}
+
+// conditionsFor computes the conditions of the OperatorConfig from the outcome
+// of a reconcile pass. This is the only place conditions are derived, every
+// exit path of Reconcile goes through here.
+func conditionsFor(oc *cranev1alpha1.OperatorConfig, result error) []metav1.Condition {
+	paused := metav1.Condition{
+		Type:               Paused,
+		Status:             metav1.ConditionFalse,
+		Reason:             NotPaused,
+		Message:            "Reconciliation is active",
+		ObservedGeneration: oc.Generation,
+	}
+	if oc.Spec.Paused {
+		// Nothing was reconciled, the other conditions are left as they were.
+		paused.Status = metav1.ConditionTrue
+		paused.Reason = PausedBySpec
+		paused.Message = "Reconciliation is paused by spec.paused"
+		return []metav1.Condition{paused}
+	}
+
+	completed := metav1.Condition{
+		Type:               ReconcileCompleted,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReconcileComplete,
+		Message:            "Reconciled successfully",
+		ObservedGeneration: oc.Generation,
+	}
+
+	degraded := metav1.Condition{
+		Type:               Degraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             AsExpected,
+		Message:            "The operator is not degraded",
+		ObservedGeneration: oc.Generation,
+	}
+
+	available := metav1.Condition{
+		Type:               Available,
+		Status:             metav1.ConditionTrue,
+		Reason:             ComponentsAvailable,
+		Message:            "All components are available",
+		ObservedGeneration: oc.Generation,
+	}
+
+	dependency := metav1.Condition{
+		Type:               DependencyMissing,
+		Status:             metav1.ConditionFalse,
+		Reason:             AllAPIsServed,
+		Message:            "All the APIs used by the operands are served",
+		ObservedGeneration: oc.Generation,
+	}
+
+	var invalidName invalidNameError
+	var unpinned unpinnedImagesError
+	var notReady certificatesNotReadyError
+	var terminating namespaceTerminatingError
+	switch {
+	case errors.As(result, &invalidName):
+		completed.Status = metav1.ConditionFalse
+		completed.Reason = InvalidName
+		completed.Message = result.Error()
+	case errors.As(result, &unpinned):
+		completed.Status = metav1.ConditionFalse
+		completed.Reason = UnpinnedImages
+		completed.Message = result.Error()
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = UnpinnedImages
+		degraded.Message = result.Error()
+	case errors.As(result, &terminating):
+		completed.Status = metav1.ConditionFalse
+		completed.Reason = NamespaceTerminating
+		completed.Message = result.Error()
+	case errors.As(result, &notReady):
+		// Everything was applied, the operands wait for their certificates
+		available.Status = metav1.ConditionFalse
+		available.Reason = CertificatesNotReady
+		available.Message = result.Error()
+	case result != nil:
+		completed.Status = metav1.ConditionFalse
+		completed.Reason = ErrorCreatingResources
+		completed.Message = result.Error()
+	}
+	if len(oc.Status.MissingAPIs) > 0 {
+		// The operands using the missing APIs were skipped
+		dependency.Status = metav1.ConditionTrue
+		dependency.Reason = APINotServed
+		dependency.Message = fmt.Sprintf("Waiting for %s to be served, the operands using them are not installed", strings.Join(oc.Status.MissingAPIs, ", "))
+		available.Status = metav1.ConditionFalse
+		available.Reason = dependency.Reason
+		available.Message = dependency.Message
+	}
+	if completed.Status == metav1.ConditionFalse {
+		available.Status = metav1.ConditionFalse
+		available.Reason = completed.Reason
+		available.Message = completed.Message
+	}
+
+	return []metav1.Condition{paused, completed, degraded, available, dependency}
+}
+
+// resetObservations clears the status fields recomputed from scratch on every
+// reconcile pass.
+func resetObservations(status *cranev1alpha1.OperatorConfigStatus) {
+	status.Drift = nil
+	status.Unmanaged = nil
+	status.InvalidOverrides = nil
+	status.InvalidDriftIgnores = nil
+	status.RewrittenImages = nil
+	status.Certificates = nil
+	status.ConsolePluginEnabled = false
+	status.MissingAPIs = nil
+}
+
+// copyObservations copies the status fields recomputed on every reconcile pass.
+func copyObservations(dst, src *cranev1alpha1.OperatorConfigStatus) {
+	dst.Drift = src.Drift
+	dst.Unmanaged = src.Unmanaged
+	dst.InvalidOverrides = src.InvalidOverrides
+	dst.InvalidDriftIgnores = src.InvalidDriftIgnores
+	dst.RewrittenImages = src.RewrittenImages
+	dst.Platform = src.Platform
+	dst.Certificates = src.Certificates
+	dst.ConsolePluginEnabled = src.ConsolePluginEnabled
+	dst.MissingAPIs = src.MissingAPIs
+}
+
+// updateStatus records the outcome of a reconcile pass on the OperatorConfig.
+// The status is merged into the cached copy of the object and written with a
+// merge patch without a resourceVersion, so concurrent spec updates or a
+// stale cache do not fail the reconcile. Nothing is written when the status
+// is unchanged.
+func (r *OperatorConfigReconciler) updateStatus(ctx context.Context, oc *cranev1alpha1.OperatorConfig, result error) error {
+	conditions := conditionsFor(oc, result)
+
+	latest := &cranev1alpha1.OperatorConfig{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(oc), latest); err != nil {
+		return err
+	}
+
+	updated := latest.DeepCopy()
+	copyObservations(&updated.Status, &oc.Status)
+	for _, condition := range conditions {
+		meta.SetStatusCondition(&updated.Status.Conditions, condition)
+	}
+
+	if equality.Semantic.DeepEqual(latest.Status, updated.Status) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, updated, client.MergeFrom(latest)); err != nil {
+		return err
+	}
+	oc.Status = updated.Status
+	return nil
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"fmt"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Status conditions", func() {
+	var oc *cranev1alpha1.OperatorConfig
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       OwnerConfigName,
+				Generation: 3,
+			},
+		}
+	})
+
+	It("reports a successful reconcile", func() {
+		conditions := conditionsFor(oc, nil)
+
+		completed := meta.FindStatusCondition(conditions, ReconcileCompleted)
+		Expect(completed).NotTo(BeNil())
+		Expect(completed.Status).To(Equal(metav1.ConditionTrue))
+		Expect(completed.Reason).To(Equal(ReconcileComplete))
+		Expect(completed.ObservedGeneration).To(Equal(int64(3)))
+	})
+
+	It("reports an invalid name", func() {
+		conditions := conditionsFor(oc, invalidNameError{name: "foo"})
+
+		completed := meta.FindStatusCondition(conditions, ReconcileCompleted)
+		Expect(completed).NotTo(BeNil())
+		Expect(completed.Status).To(Equal(metav1.ConditionFalse))
+		Expect(completed.Reason).To(Equal(InvalidName))
+	})
+
+	It("reports errors creating resources", func() {
+		conditions := conditionsFor(oc, fmt.Errorf("boom"))
+
+		completed := meta.FindStatusCondition(conditions, ReconcileCompleted)
+		Expect(completed).NotTo(BeNil())
+		Expect(completed.Status).To(Equal(metav1.ConditionFalse))
+		Expect(completed.Reason).To(Equal(ErrorCreatingResources))
+		Expect(completed.Message).To(Equal("boom"))
+	})
+
+	It("reports unpinned images as degraded", func() {
+		conditions := conditionsFor(oc, unpinnedImagesError{images: []string{"quay.io/konveyor/crane-runner:latest"}})
+
+		Expect(meta.IsStatusConditionFalse(conditions, ReconcileCompleted)).To(BeTrue())
+		degraded := meta.FindStatusCondition(conditions, Degraded)
+		Expect(degraded).NotTo(BeNil())
+		Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(UnpinnedImages))
+		Expect(degraded.Message).To(ContainSubstring("quay.io/konveyor/crane-runner:latest"))
+	})
+
+	It("is not available while waiting for certificates", func() {
+		conditions := conditionsFor(oc, certificatesNotReadyError{secrets: []string{"crane-reverse-proxy-certs"}})
+
+		Expect(meta.IsStatusConditionTrue(conditions, ReconcileCompleted)).To(BeTrue())
+		available := meta.FindStatusCondition(conditions, Available)
+		Expect(available).NotTo(BeNil())
+		Expect(available.Status).To(Equal(metav1.ConditionFalse))
+		Expect(available.Reason).To(Equal(CertificatesNotReady))
+	})
+
+	It("reports a terminating install namespace", func() {
+		conditions := conditionsFor(oc, namespaceTerminatingError{namespace: InstallNamespace})
+
+		completed := meta.FindStatusCondition(conditions, ReconcileCompleted)
+		Expect(completed).NotTo(BeNil())
+		Expect(completed.Status).To(Equal(metav1.ConditionFalse))
+		Expect(completed.Reason).To(Equal(NamespaceTerminating))
+		Expect(meta.IsStatusConditionFalse(conditions, Available)).To(BeTrue())
+	})
+
+	It("reports the missing APIs", func() {
+		oc.Status.MissingAPIs = []string{"tekton.dev/v1beta1 ClusterTask"}
+		conditions := conditionsFor(oc, nil)
+
+		Expect(meta.IsStatusConditionTrue(conditions, ReconcileCompleted)).To(BeTrue())
+		dependency := meta.FindStatusCondition(conditions, DependencyMissing)
+		Expect(dependency).NotTo(BeNil())
+		Expect(dependency.Status).To(Equal(metav1.ConditionTrue))
+		Expect(dependency.Reason).To(Equal(APINotServed))
+		Expect(dependency.Message).To(ContainSubstring("tekton.dev/v1beta1 ClusterTask"))
+		Expect(meta.IsStatusConditionFalse(conditions, Available)).To(BeTrue())
+	})
+
+	It("only reports the paused condition while paused", func() {
+		oc.Spec.Paused = true
+		conditions := conditionsFor(oc, nil)
+
+		Expect(conditions).To(HaveLen(1))
+		Expect(meta.IsStatusConditionTrue(conditions, Paused)).To(BeTrue())
+	})
+})
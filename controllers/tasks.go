@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var taskGVK = pipelinev1beta1.SchemeGroupVersion.WithKind("Task")
+
+// taskKinds returns whether the crane-runner tasks are installed as
+// ClusterTasks, as namespaced Tasks, or both.
+func taskKinds(oc *cranev1alpha1.OperatorConfig) (clusterTasks bool, tasks bool) {
+	if oc.Spec.Tasks == nil {
+		return true, false
+	}
+	switch oc.Spec.Tasks.Kind {
+	case cranev1alpha1.TaskKindTask:
+		return false, true
+	case cranev1alpha1.TaskKindBoth:
+		return true, true
+	}
+	return true, false
+}
+
+// taskNamespaces returns the namespaces the Tasks are installed in.
+func taskNamespaces(oc *cranev1alpha1.OperatorConfig) []string {
+	if oc.Spec.Tasks == nil || len(oc.Spec.Tasks.Namespaces) == 0 {
+		return []string{InstallNamespace}
+	}
+	return oc.Spec.Tasks.Namespaces
+}
+
+// expandTasks replaces the rendered ClusterTasks with the kinds of tasks
+// selected by spec.tasks, a Task in each of the namespaces.
+func expandTasks(objs []*unstructured.Unstructured, oc *cranev1alpha1.OperatorConfig) []*unstructured.Unstructured {
+	clusterTasks, tasks := taskKinds(oc)
+
+	var expanded []*unstructured.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() != "ClusterTask" {
+			expanded = append(expanded, obj)
+			continue
+		}
+		if clusterTasks {
+			expanded = append(expanded, obj)
+		}
+		if !tasks {
+			continue
+		}
+		for _, namespace := range taskNamespaces(oc) {
+			task := obj.DeepCopy()
+			task.SetKind(taskGVK.Kind)
+			task.SetNamespace(namespace)
+			expanded = append(expanded, task)
+		}
+	}
+	return expanded
+}
+
+func (r *OperatorConfigReconciler) reconcileTask(resource *unstructured.Unstructured, ctx context.Context, imageFn ImageFunction, log logr.Logger, oc *cranev1alpha1.OperatorConfig) error {
+	var obj pipelinev1beta1.Task
+	err := runtime.DefaultUnstructuredConverter.
+		FromUnstructured(resource.UnstructuredContent(), &obj)
+	if err != nil {
+		return err
+	}
+
+	task := &pipelinev1beta1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: obj.Namespace, Name: obj.Name}}
+	op, err := r.createOrPatch(ctx, oc, task, func() error {
+		err = controllerutil.SetControllerReference(oc, task, r.Scheme)
+		if err != nil {
+			return err
+		}
+
+		task.Spec = obj.Spec
+		if len(obj.Labels) > 0 {
+			task.Labels = obj.Labels
+		}
+		if len(obj.Annotations) > 0 {
+			task.Annotations = obj.Annotations
+		}
+		return prepareTaskSpec(&task.Spec, obj.Annotations, imageFn, oc)
+	})
+	if err != nil {
+		return err
+	} else {
+		log.Info("Task successfully reconciled", "operation", op)
+	}
+
+	return nil
+}
+
+// prepareTaskSpec sets the images of the steps of a ClusterTask or Task and
+// applies the image and TLS settings of the OperatorConfig.
+func prepareTaskSpec(taskSpec *pipelinev1beta1.TaskSpec, annotations map[string]string, imageFn ImageFunction, oc *cranev1alpha1.OperatorConfig) error {
+	for i := range taskSpec.Steps {
+		image, err := imageFor(annotations, taskSpec.Steps[i].Name, imageFn)
+		if err != nil {
+			return err
+		}
+		taskSpec.Steps[i].Image = rewriteImage(oc, image)
+		taskSpec.Steps[i].Script = rewriteScriptImages(oc, taskSpec.Steps[i].Script)
+		if oc.Spec.TrustedCA != nil && oc.Spec.TrustedCA.VerifyRemoteTLS {
+			taskSpec.Steps[i].Script = dropInsecureTLS(taskSpec.Steps[i].Script)
+		}
+	}
+	applyTaskPullSettings(taskSpec, oc)
+	return nil
+}
+
+// pruneTasks deletes the ClusterTasks and Tasks no longer selected by
+// spec.tasks, e.g. the Tasks of a namespace removed from the list.
+func (r *OperatorConfigReconciler) pruneTasks(ctx context.Context, log logr.Logger, oc *cranev1alpha1.OperatorConfig, rendered []renderedOperand) error {
+	desired := map[string]bool{}
+	for _, o := range rendered {
+		for _, obj := range o.objs {
+			desired[obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName()] = true
+		}
+	}
+
+	var lists []client.ObjectList
+	if served(r.restMapper(), clusterTaskGVK) {
+		lists = append(lists, &pipelinev1beta1.ClusterTaskList{})
+	}
+	if served(r.restMapper(), taskGVK) {
+		lists = append(lists, &pipelinev1beta1.TaskList{})
+	}
+	for _, list := range lists {
+		err := r.List(ctx, list)
+		if err != nil {
+			return err
+		}
+		objs, err := apimeta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			obj := o.(client.Object)
+			kind := "ClusterTask"
+			if _, ok := obj.(*pipelinev1beta1.Task); ok {
+				kind = taskGVK.Kind
+			}
+			if desired[kind+"/"+obj.GetNamespace()+"/"+obj.GetName()] || !metav1.IsControlledBy(obj, oc) {
+				continue
+			}
+			unmanaged, err := r.unmanaged(oc, obj)
+			if err != nil {
+				return err
+			}
+			if unmanaged {
+				continue
+			}
+			err = r.Delete(ctx, obj)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			log.Info("Removed task no longer selected", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+	}
+	return nil
+}
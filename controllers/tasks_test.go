@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Tasks", func() {
+	var (
+		oc   *cranev1alpha1.OperatorConfig
+		objs []*unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		oc = &cranev1alpha1.OperatorConfig{ObjectMeta: metav1.ObjectMeta{Name: OwnerConfigName, UID: "test"}}
+		objs = []*unstructured.Unstructured{unstructuredOf(clusterTaskGVK), unstructuredOf(clusterTaskGVK)}
+		objs[0].SetName("crane-export")
+		objs[1].SetName("crane-transform")
+	})
+
+	It("installs ClusterTasks by default", func() {
+		Expect(expandTasks(objs, oc)).To(Equal(objs))
+	})
+
+	It("installs Tasks into the install namespace", func() {
+		oc.Spec.Tasks = &cranev1alpha1.TasksConfig{Kind: cranev1alpha1.TaskKindTask}
+
+		expanded := expandTasks(objs, oc)
+		Expect(expanded).To(HaveLen(2))
+		for _, task := range expanded {
+			Expect(task.GroupVersionKind()).To(Equal(taskGVK))
+			Expect(task.GetNamespace()).To(Equal(InstallNamespace))
+		}
+		Expect(objs[0].GetKind()).To(Equal("ClusterTask"))
+	})
+
+	It("installs both kinds into the selected namespaces", func() {
+		oc.Spec.Tasks = &cranev1alpha1.TasksConfig{
+			Kind:       cranev1alpha1.TaskKindBoth,
+			Namespaces: []string{"team-a", "team-b"},
+		}
+
+		var names []string
+		for _, obj := range expandTasks(objs, oc) {
+			names = append(names, obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName())
+		}
+		Expect(names).To(Equal([]string{
+			"ClusterTask//crane-export",
+			"Task/team-a/crane-export",
+			"Task/team-b/crane-export",
+			"ClusterTask//crane-transform",
+			"Task/team-a/crane-transform",
+			"Task/team-b/crane-transform",
+		}))
+	})
+
+	It("only mounts the trusted CA bundle into the Tasks of the install namespace", func() {
+		oc.Status.Platform = cranev1alpha1.PlatformOpenShift
+		oc.Spec.Tasks = &cranev1alpha1.TasksConfig{
+			Kind:       cranev1alpha1.TaskKindTask,
+			Namespaces: []string{InstallNamespace, "team-a"},
+		}
+		data := manifestData{Namespace: InstallNamespace, Images: resolvedImages(), TLS: tlsConfigFor(nil)}
+		rendered, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-runner.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, obj := range expandTasks(rendered, oc) {
+			Expect(injectTrustedCA(obj, oc)).To(Succeed())
+			task := &pipelinev1beta1.Task{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, task)).To(Succeed())
+
+			mounted := ContainElement(HaveField("Name", TrustedCABundleConfigMap))
+			if task.Namespace != InstallNamespace {
+				mounted = Not(mounted)
+			}
+			Expect(task.Spec.Volumes).To(mounted, task.Namespace+"/"+task.Name)
+			for _, step := range task.Spec.Steps {
+				Expect(step.VolumeMounts).To(mounted, task.Namespace+"/"+task.Name)
+				Expect(step.Env).NotTo(ContainElement(HaveField("Name", "SSL_CERT_FILE")), task.Namespace+"/"+task.Name)
+				if task.Namespace == InstallNamespace {
+					Expect(step.Script).To(HavePrefix("if [ -s /etc/crane/trusted-ca/tls-ca-bundle.pem ]; then export SSL_CERT_FILE="), task.Name)
+				} else {
+					Expect(step.Script).NotTo(ContainSubstring("SSL_CERT_FILE"), task.Name)
+				}
+			}
+		}
+	})
+
+	It("removes the tasks no longer selected", func() {
+		s := runtime.NewScheme()
+		Expect(pipelinev1beta1.AddToScheme(s)).To(Succeed())
+		Expect(cranev1alpha1.AddToScheme(s)).To(Succeed())
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(clusterTaskGVK, meta.RESTScopeRoot)
+		mapper.Add(taskGVK, meta.RESTScopeNamespace)
+		r := &OperatorConfigReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithRESTMapper(mapper).Build(), Scheme: s}
+
+		clusterTask := &pipelinev1beta1.ClusterTask{ObjectMeta: metav1.ObjectMeta{Name: "crane-export"}}
+		kept := &pipelinev1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "crane-export", Namespace: "team-a"}}
+		removed := &pipelinev1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "crane-export", Namespace: "team-b"}}
+		foreign := &pipelinev1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "git-clone", Namespace: "team-b"}}
+		unmanaged := &pipelinev1beta1.Task{ObjectMeta: metav1.ObjectMeta{
+			Name:        "crane-export",
+			Namespace:   "team-c",
+			Annotations: map[string]string{UnmanagedAnnotation: "true"},
+		}}
+		for _, obj := range []client.Object{clusterTask, kept, removed, unmanaged} {
+			Expect(controllerutil.SetControllerReference(oc, obj, s)).To(Succeed())
+			Expect(r.Create(context.TODO(), obj)).To(Succeed())
+		}
+		Expect(r.Create(context.TODO(), foreign)).To(Succeed())
+
+		oc.Spec.Tasks = &cranev1alpha1.TasksConfig{Kind: cranev1alpha1.TaskKindTask, Namespaces: []string{"team-a"}}
+		rendered := []renderedOperand{{objs: expandTasks(objs[:1], oc)}}
+		Expect(r.pruneTasks(context.TODO(), log.FromContext(context.TODO()), oc, rendered)).To(Succeed())
+
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(kept), kept)).To(Succeed())
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(removed), removed)).NotTo(Succeed())
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(clusterTask), clusterTask)).NotTo(Succeed())
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(unmanaged), unmanaged)).To(Succeed())
+		Expect(oc.Status.Unmanaged).To(ConsistOf(cranev1alpha1.ResourceReference{Kind: "Task", Name: "crane-export", Namespace: "team-c"}))
+	})
+})
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// clusterAPIServerName is the name of the cluster wide config.openshift.io
+// APIServer
+const clusterAPIServerName = "cluster"
+
+var apiServerGVK = configv1.GroupVersion.WithKind("APIServer")
+
+// TLSConfig are the TLS settings of the operand servers, available to the
+// manifests as .TLS
+type TLSConfig struct {
+	// MinTLSVersion is the minimum TLS version of the Go servers, e.g.
+	// VersionTLS12
+	MinTLSVersion string
+	// CipherSuites are the comma separated IANA names of the cipher suites
+	// of the Go servers
+	CipherSuites string
+	// NginxProtocols is the ssl_protocols directive of nginx, e.g.
+	// TLSv1.2 TLSv1.3
+	NginxProtocols string
+	// NginxCiphers is the ssl_ciphers directive of nginx in OpenSSL format
+	NginxCiphers string
+}
+
+// opensslToIANA maps the OpenSSL names of the cipher suites of the TLS
+// security profiles to the IANA names the Go servers understand. Suites Go
+// does not implement, like the DHE ones, are left out.
+var opensslToIANA = map[string]string{
+	"TLS_AES_128_GCM_SHA256":        "TLS_AES_128_GCM_SHA256",
+	"TLS_AES_256_GCM_SHA384":        "TLS_AES_256_GCM_SHA384",
+	"TLS_CHACHA20_POLY1305_SHA256":  "TLS_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-GCM-SHA256": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256":   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384":   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-ECDSA-CHACHA20-POLY1305": "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-RSA-CHACHA20-POLY1305":   "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-SHA256":     "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-RSA-AES128-SHA256":       "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-ECDSA-AES128-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-RSA-AES128-SHA":          "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-ECDSA-AES256-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	"ECDHE-RSA-AES256-SHA":          "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	"AES128-GCM-SHA256":             "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	"AES256-GCM-SHA384":             "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	"AES128-SHA256":                 "TLS_RSA_WITH_AES_128_CBC_SHA256",
+	"AES128-SHA":                    "TLS_RSA_WITH_AES_128_CBC_SHA",
+	"AES256-SHA":                    "TLS_RSA_WITH_AES_256_CBC_SHA",
+	"DES-CBC3-SHA":                  "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+}
+
+// nginxProtocols are the ssl_protocols of each minimum TLS version
+var nginxProtocols = map[configv1.TLSProtocolVersion]string{
+	configv1.VersionTLS10: "TLSv1 TLSv1.1 TLSv1.2 TLSv1.3",
+	configv1.VersionTLS11: "TLSv1.1 TLSv1.2 TLSv1.3",
+	configv1.VersionTLS12: "TLSv1.2 TLSv1.3",
+	configv1.VersionTLS13: "TLSv1.3",
+}
+
+// tlsConfig returns the TLS settings of the operands. spec.tlsSecurityProfile
+// takes precedence over the profile of the cluster wide APIServer, the
+// Intermediate profile is used when neither is set.
+func (r *OperatorConfigReconciler) tlsConfig(ctx context.Context, oc *cranev1alpha1.OperatorConfig) (TLSConfig, error) {
+	profile := oc.Spec.TLSSecurityProfile
+	if profile == nil && served(r.restMapper(), apiServerGVK) {
+		apiServer := &configv1.APIServer{}
+		err := r.Get(ctx, types.NamespacedName{Name: clusterAPIServerName}, apiServer)
+		if err != nil && !errors.IsNotFound(err) {
+			return TLSConfig{}, err
+		}
+		profile = apiServer.Spec.TLSSecurityProfile
+	}
+	return tlsConfigFor(profile), nil
+}
+
+// tlsConfigFor returns the TLS settings of a TLS security profile.
+func tlsConfigFor(profile *configv1.TLSSecurityProfile) TLSConfig {
+	spec := configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+	if profile != nil {
+		if profile.Type == configv1.TLSProfileCustomType {
+			if profile.Custom != nil {
+				spec = &profile.Custom.TLSProfileSpec
+			}
+		} else if predefined, ok := configv1.TLSProfiles[profile.Type]; ok {
+			spec = predefined
+		}
+	}
+
+	var suites, ciphers []string
+	for _, cipher := range spec.Ciphers {
+		if suite, ok := opensslToIANA[cipher]; ok {
+			suites = append(suites, suite)
+		}
+		// The TLS 1.3 suites are not configured with ssl_ciphers
+		if !strings.HasPrefix(cipher, "TLS_") {
+			ciphers = append(ciphers, cipher)
+		}
+	}
+
+	config := TLSConfig{
+		MinTLSVersion:  string(spec.MinTLSVersion),
+		CipherSuites:   strings.Join(suites, ","),
+		NginxProtocols: nginxProtocols[spec.MinTLSVersion],
+		NginxCiphers:   strings.Join(ciphers, ":"),
+	}
+	if config.NginxProtocols == "" {
+		config.NginxProtocols = nginxProtocols[configv1.VersionTLS12]
+	}
+	if config.NginxCiphers == "" {
+		// Only TLS 1.3 suites, use the nginx default for older versions
+		config.NginxCiphers = "HIGH:!aNULL:!MD5"
+	}
+	return config
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("TLS security profile", func() {
+	It("defaults to the Intermediate profile", func() {
+		config := tlsConfigFor(nil)
+		Expect(config.MinTLSVersion).To(Equal("VersionTLS12"))
+		Expect(config.NginxProtocols).To(Equal("TLSv1.2 TLSv1.3"))
+		Expect(config.CipherSuites).To(ContainSubstring("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"))
+		Expect(config.NginxCiphers).To(ContainSubstring("ECDHE-RSA-AES128-GCM-SHA256"))
+		Expect(config.NginxCiphers).NotTo(ContainSubstring("TLS_AES_128_GCM_SHA256"))
+	})
+
+	It("uses the profile of the spec", func() {
+		r := &OperatorConfigReconciler{Client: c, Scheme: scheme.Scheme}
+		oc := &cranev1alpha1.OperatorConfig{Spec: cranev1alpha1.OperatorConfigSpec{
+			TLSSecurityProfile: &configv1.TLSSecurityProfile{
+				Type: configv1.TLSProfileCustomType,
+				Custom: &configv1.CustomTLSProfile{TLSProfileSpec: configv1.TLSProfileSpec{
+					Ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384", "DHE-RSA-AES256-GCM-SHA384"},
+					MinTLSVersion: configv1.VersionTLS11,
+				}},
+			},
+		}}
+
+		config, err := r.tlsConfig(context.TODO(), oc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(TLSConfig{
+			MinTLSVersion:  "VersionTLS11",
+			CipherSuites:   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+			NginxProtocols: "TLSv1.1 TLSv1.2 TLSv1.3",
+			NginxCiphers:   "ECDHE-RSA-AES256-GCM-SHA384:DHE-RSA-AES256-GCM-SHA384",
+		}))
+	})
+
+	It("renders the settings into the nginx configuration", func() {
+		data := manifestData{
+			Namespace: "crane-test",
+			Images:    resolvedImages(),
+			TLS:       tlsConfigFor(nil),
+			Platform:  cranev1alpha1.PlatformOpenShift,
+		}
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-ui-plugin.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, obj := range objs {
+			if obj.GetName() != "nginx-conf" {
+				continue
+			}
+			configMap := &corev1.ConfigMap{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, configMap)).To(Succeed())
+			Expect(configMap.Data["nginx.conf"]).To(ContainSubstring("ssl_protocols       TLSv1.2 TLSv1.3;"))
+			Expect(configMap.Data["nginx.conf"]).To(ContainSubstring("ssl_ciphers         " + data.TLS.NginxCiphers + ";"))
+		}
+	})
+})
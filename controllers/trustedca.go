@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// TrustedCABundleConfigMap is the ConfigMap holding the CA bundle the
+	// operands trust, see trusted-ca-bundle.yaml
+	TrustedCABundleConfigMap = "crane-trusted-ca-bundle"
+
+	// InjectTrustedCABundleLabel asks the cluster network operator to write
+	// the trusted CA bundle of the cluster into a ConfigMap
+	InjectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// trustedCAMountPath is where the trusted CA bundle is mounted, next to
+	// the system CA bundle of the images rather than on top of it
+	trustedCAMountPath = "/etc/crane/trusted-ca"
+
+	// trustedCAFile is the name of the mounted bundle, SSL_CERT_FILE points
+	// the Go and OpenSSL based operands to it
+	trustedCAFile = "tls-ca-bundle.pem"
+)
+
+var insecureTLSFlag = regexp.MustCompile(`\s*--insecure-skip-tls-verify(=true)?\b`)
+
+// injectTrustedCA mounts the trusted CA bundle into every container of a
+// rendered Deployment and every step of a Task of the install namespace, and
+// points SSL_CERT_FILE to it. Nothing is mounted unless a bundle is set in the
+// spec or injected by OpenShift, the operands then use the CA bundle of their
+// image. ClusterTask steps, and the steps of Tasks of other namespaces, run
+// where the bundle ConfigMap does not exist, they keep the CA bundle of the
+// crane-runner image.
+func injectTrustedCA(resource *unstructured.Unstructured, oc *cranev1alpha1.OperatorConfig) error {
+	if !trustedCAProvided(oc) || !runsInInstallNamespace(resource) {
+		return nil
+	}
+
+	optional := true
+	volume := corev1.Volume{
+		Name: TrustedCABundleConfigMap,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: TrustedCABundleConfigMap},
+				Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: trustedCAFile}},
+				// OpenShift injects the bundle after the ConfigMap is created
+				Optional: &optional,
+			},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:      TrustedCABundleConfigMap,
+		MountPath: trustedCAMountPath,
+		ReadOnly:  true,
+	}
+	if err := injectVolume(resource, volume, mount); err != nil {
+		return err
+	}
+	caFile := path.Join(trustedCAMountPath, trustedCAFile)
+	if resource.GetKind() == "Task" {
+		// Pipelines of other namespaces can run the Task through the cluster
+		// resolver, the bundle is then missing and must not be used.
+		return updateContainers(resource, func(step map[string]interface{}) error {
+			if script, ok := step["script"].(string); ok {
+				step["script"] = useTrustedCA(script, caFile)
+			}
+			return nil
+		})
+	}
+	return injectEnv(resource, []corev1.EnvVar{{Name: "SSL_CERT_FILE", Value: caFile}})
+}
+
+// useTrustedCA prepends a step script with the export of SSL_CERT_FILE, when
+// the CA bundle file is mounted and not empty.
+func useTrustedCA(script, caFile string) string {
+	export := fmt.Sprintf("if [ -s %[1]s ]; then export SSL_CERT_FILE=%[1]s; fi\n", caFile)
+	if !strings.HasPrefix(script, "#!") {
+		return export + script
+	}
+	shebang, rest, _ := strings.Cut(script, "\n")
+	return shebang + "\n" + export + rest
+}
+
+// runsInInstallNamespace returns whether the pods of a rendered resource run
+// in the install namespace, next to the trusted CA bundle ConfigMap.
+func runsInInstallNamespace(resource *unstructured.Unstructured) bool {
+	switch resource.GetKind() {
+	case "Deployment":
+		return true
+	case "Task":
+		return resource.GetNamespace() == InstallNamespace
+	}
+	return false
+}
+
+// trustedCAProvided returns whether the trusted CA bundle ConfigMap holds a
+// bundle: either the one of the spec or the one OpenShift injects.
+func trustedCAProvided(oc *cranev1alpha1.OperatorConfig) bool {
+	if oc.Spec.TrustedCA != nil && oc.Spec.TrustedCA.CABundle != "" {
+		return true
+	}
+	return oc.Status.Platform == cranev1alpha1.PlatformOpenShift
+}
+
+// dropInsecureTLS removes the flags disabling certificate verification from a
+// ClusterTask script.
+func dropInsecureTLS(script string) string {
+	return insecureTLSFlag.ReplaceAllString(script, "")
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"path/filepath"
+
+	cranev1alpha1 "github.com/konveyor/crane-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Trusted CA", func() {
+	var data manifestData
+
+	BeforeEach(func() {
+		data = manifestData{Namespace: "crane-test", Images: resolvedImages(), TLS: tlsConfigFor(nil)}
+	})
+
+	renderBundle := func() *corev1.ConfigMap {
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "trusted-ca-bundle.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(objs[0].Object, configMap)).To(Succeed())
+		return configMap
+	}
+
+	It("asks for the cluster CA bundle to be injected by default", func() {
+		configMap := renderBundle()
+		Expect(configMap.Labels).To(HaveKeyWithValue(InjectTrustedCABundleLabel, "true"))
+		Expect(configMap.Data).To(BeEmpty())
+	})
+
+	It("uses the CA bundle of the spec", func() {
+		bundle := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+		data.Spec.TrustedCA = &cranev1alpha1.TrustedCAConfig{CABundle: bundle}
+
+		configMap := renderBundle()
+		Expect(configMap.Labels).NotTo(HaveKey(InjectTrustedCABundleLabel))
+		Expect(configMap.Data).To(HaveKeyWithValue("ca-bundle.crt", bundle))
+	})
+
+	renderProxy := func(oc *cranev1alpha1.OperatorConfig) *appsv1.Deployment {
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-reverse-proxy.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, obj := range objs {
+			if obj.GetKind() != "Deployment" {
+				continue
+			}
+			Expect(injectTrustedCA(obj, oc)).To(Succeed())
+			deployment := &appsv1.Deployment{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, deployment)).To(Succeed())
+			return deployment
+		}
+		Fail("the proxy has no Deployment")
+		return nil
+	}
+
+	It("mounts the CA bundle of the spec into the operand containers", func() {
+		oc := &cranev1alpha1.OperatorConfig{}
+		oc.Spec.TrustedCA = &cranev1alpha1.TrustedCAConfig{CABundle: "-----BEGIN CERTIFICATE-----\n"}
+
+		deployment := renderProxy(oc)
+		Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", TrustedCABundleConfigMap)))
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      TrustedCABundleConfigMap,
+				MountPath: "/etc/crane/trusted-ca",
+				ReadOnly:  true,
+			}), container.Name)
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{
+				Name:  "SSL_CERT_FILE",
+				Value: "/etc/crane/trusted-ca/tls-ca-bundle.pem",
+			}), container.Name)
+		}
+	})
+
+	It("mounts the CA bundle injected on OpenShift", func() {
+		oc := &cranev1alpha1.OperatorConfig{}
+		oc.Status.Platform = cranev1alpha1.PlatformOpenShift
+
+		deployment := renderProxy(oc)
+		Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", TrustedCABundleConfigMap)))
+	})
+
+	It("keeps the CA bundle of the images when none is provided", func() {
+		oc := &cranev1alpha1.OperatorConfig{}
+		oc.Status.Platform = cranev1alpha1.PlatformKubernetes
+
+		deployment := renderProxy(oc)
+		Expect(deployment.Spec.Template.Spec.Volumes).NotTo(ContainElement(HaveField("Name", TrustedCABundleConfigMap)))
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			Expect(container.VolumeMounts).NotTo(ContainElement(HaveField("Name", TrustedCABundleConfigMap)), container.Name)
+			Expect(container.Env).NotTo(ContainElement(HaveField("Name", "SSL_CERT_FILE")), container.Name)
+		}
+	})
+
+	It("leaves the ClusterTasks, which run outside of the install namespace, alone", func() {
+		oc := &cranev1alpha1.OperatorConfig{}
+		oc.Status.Platform = cranev1alpha1.PlatformOpenShift
+
+		objs, err := renderManifests(filepath.Join("..", "deploy", "artifacts", "crane-runner.yaml"), data)
+		Expect(err).NotTo(HaveOccurred())
+		for _, obj := range objs {
+			Expect(injectTrustedCA(obj, oc)).To(Succeed())
+
+			task := &pipelinev1beta1.ClusterTask{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, task)).To(Succeed())
+			Expect(task.Spec.Volumes).NotTo(ContainElement(HaveField("Name", TrustedCABundleConfigMap)), task.Name)
+			for _, step := range task.Spec.Steps {
+				Expect(step.Env).NotTo(ContainElement(HaveField("Name", "SSL_CERT_FILE")), task.Name)
+			}
+		}
+	})
+
+	It("keeps the shebang of Task scripts first", func() {
+		Expect(useTrustedCA("#!/bin/bash\necho", "/ca.pem")).To(Equal("#!/bin/bash\nif [ -s /ca.pem ]; then export SSL_CERT_FILE=/ca.pem; fi\necho"))
+		Expect(useTrustedCA("echo", "/ca.pem")).To(Equal("if [ -s /ca.pem ]; then export SSL_CERT_FILE=/ca.pem; fi\necho"))
+	})
+
+	It("drops insecure TLS flags from scripts", func() {
+		script := "oc login --insecure-skip-tls-verify --token=$CLUSTER_TOKEN $CLUSTER_URL"
+		Expect(dropInsecureTLS(script)).To(Equal("oc login --token=$CLUSTER_TOKEN $CLUSTER_URL"))
+	})
+})